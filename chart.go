@@ -0,0 +1,319 @@
+package pptx
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// ChartType is the kind of a Chart.
+type ChartType string
+
+const (
+	BarChart     ChartType = "bar"     // Horizontal bars.
+	ColumnChart  ChartType = "col"     // Vertical bars.
+	LineChart    ChartType = "line"    // Lines over categories.
+	ScatterChart ChartType = "scatter" // Lines over x values.
+	PieChart     ChartType = "pie"     // Pie of the first series.
+	AreaChart    ChartType = "area"    // Filled areas over categories.
+)
+
+// A Chart is a native PowerPoint chart.
+// The data is stored in an embedded workbook, which can be edited in PowerPoint.
+type Chart struct {
+	X, Y, W, H     Dimension
+	Type           ChartType
+	Title          string
+	Categories     []string // Category labels, not used for scatter charts.
+	Series         []Series
+	XTitle, YTitle string // Axis titles.
+	Legend         string // Legend position: "r", "l", "t" or "b". Empty for no legend.
+}
+
+// Series is a data series of a chart.
+type Series struct {
+	Name   string
+	Values []float64
+	X      []float64   // X values for scatter charts.
+	Color  color.Color // Nil uses the theme color.
+}
+
+// addChart adds a chart and it's embedded workbook to the slide.
+func (s *Slide) addChart(f *File, c Chart) error {
+	chart, data, err := c.build()
+	if err != nil {
+		return err
+	}
+	part := s.addPart(f, "ppt/charts/chart1.xml", chart, ctChart, true)
+	xlsx := s.addPart(f, "ppt/embeddings/Microsoft_Excel_Worksheet1.xlsx", rawFile(data), ctXlsx, false)
+	rels := emptyRels()
+	e := rels.SelectElement("Relationships").CreateElement("Relationship")
+	e.CreateAttr("Id", "rId1")
+	e.CreateAttr("Type", relTypePackage)
+	e.CreateAttr("Target", relativeTarget(part, xlsx))
+	s.addPart(f, relsName(part), rels, "", false)
+	rId := s.addRel(relTypeChart, part, false)
+
+	id, name := s.ids.new("Chart", "")
+	frame := etree.NewElement("p:graphicFrame")
+	nv := frame.CreateElement("p:nvGraphicFramePr")
+	cNvPr := nv.CreateElement("p:cNvPr")
+	cNvPr.CreateAttr("id", strconv.Itoa(id))
+	cNvPr.CreateAttr("name", name)
+	nv.CreateElement("p:cNvGraphicFramePr")
+	nv.CreateElement("p:nvPr")
+	xfrm := frame.CreateElement("p:xfrm")
+	setPoint(xfrm.CreateElement("a:off"), "x", "y", c.X, c.Y)
+	setPoint(xfrm.CreateElement("a:ext"), "cx", "cy", c.W, c.H)
+	gd := frame.CreateElement("a:graphic").CreateElement("a:graphicData")
+	gd.CreateAttr("uri", "http://schemas.openxmlformats.org/drawingml/2006/chart")
+	ce := gd.CreateElement("c:chart")
+	ce.CreateAttr("xmlns:c", "http://schemas.openxmlformats.org/drawingml/2006/chart")
+	ce.CreateAttr("r:id", rId)
+
+	spTree := s.xml.FindElement("p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return fmt.Errorf("Cannot find spTree")
+	}
+	spTree.AddChild(frame)
+	return nil
+}
+
+// build returns the chart part (c:chartSpace) and the embedded workbook.
+func (c Chart) build() (*etree.Document, []byte, error) {
+	if len(c.Series) == 0 {
+		return nil, nil, fmt.Errorf("chart has no series")
+	}
+	var group string
+	switch c.Type {
+	case BarChart, ColumnChart:
+		group = "c:barChart"
+	case LineChart:
+		group = "c:lineChart"
+	case ScatterChart:
+		group = "c:scatterChart"
+	case PieChart:
+		group = "c:pieChart"
+	case AreaChart:
+		group = "c:areaChart"
+	default:
+		return nil, nil, fmt.Errorf("unknown chart type: %q", c.Type)
+	}
+
+	// Worksheet layout: categories in column A and series in columns B, C, ...
+	// Scatter charts have x and y columns for each series.
+	n := len(c.Categories)
+	for _, s := range c.Series {
+		if len(s.Values) > n {
+			n = len(s.Values)
+		}
+		if c.Type == ScatterChart && len(s.X) != len(s.Values) {
+			return nil, nil, fmt.Errorf("scatter series %q has %d x and %d y values", s.Name, len(s.X), len(s.Values))
+		}
+	}
+	sheet := make([][]xlsxCell, n+1)
+	cols := len(c.Series) + 1
+	if c.Type == ScatterChart {
+		cols = 2 * len(c.Series)
+	}
+	for i := range sheet {
+		sheet[i] = make([]xlsxCell, cols)
+	}
+	for i, s := range c.Categories {
+		sheet[i+1][0] = xlsxCell{s: s}
+	}
+	for k, s := range c.Series {
+		x, y := 0, k+1
+		if c.Type == ScatterChart {
+			x, y = 2*k, 2*k+1
+			sheet[0][x] = xlsxCell{s: "X"}
+			for i, v := range s.X {
+				sheet[i+1][x] = xlsxCell{v: v, isNum: true}
+			}
+		}
+		sheet[0][y] = xlsxCell{s: s.Name}
+		for i, v := range s.Values {
+			sheet[i+1][y] = xlsxCell{v: v, isNum: true}
+		}
+	}
+	data, err := xlsx(sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := etree.NewDocument()
+	d.CreateProcInst("xml", `version="1.0" encoding="UTF-8" standalone="yes"`)
+	cs := d.CreateElement("c:chartSpace")
+	cs.CreateAttr("xmlns:c", "http://schemas.openxmlformats.org/drawingml/2006/chart")
+	cs.CreateAttr("xmlns:a", "http://schemas.openxmlformats.org/drawingml/2006/main")
+	cs.CreateAttr("xmlns:r", "http://schemas.openxmlformats.org/officeDocument/2006/relationships")
+	val(cs, "c:roundedCorners", "0")
+	chart := cs.CreateElement("c:chart")
+	if c.Title != "" {
+		chartTitle(chart, c.Title)
+		val(chart, "c:autoTitleDeleted", "0")
+	} else {
+		val(chart, "c:autoTitleDeleted", "1")
+	}
+	plot := chart.CreateElement("c:plotArea")
+	plot.CreateElement("c:layout")
+	g := plot.CreateElement(group)
+	switch c.Type {
+	case BarChart:
+		val(g, "c:barDir", "bar")
+		val(g, "c:grouping", "clustered")
+	case ColumnChart:
+		val(g, "c:barDir", "col")
+		val(g, "c:grouping", "clustered")
+	case LineChart, AreaChart:
+		val(g, "c:grouping", "standard")
+	case ScatterChart:
+		val(g, "c:scatterStyle", "lineMarker")
+	}
+	val(g, "c:varyColors", map[bool]string{true: "1", false: "0"}[c.Type == PieChart])
+
+	catRef := fmt.Sprintf("Sheet1!$A$2:$A$%d", n+1)
+	for k, s := range c.Series {
+		x, y := "A", colName(k+1)
+		if c.Type == ScatterChart {
+			x, y = colName(2*k), colName(2*k+1)
+		}
+		ser := g.CreateElement("c:ser")
+		val(ser, "c:idx", strconv.Itoa(k))
+		val(ser, "c:order", strconv.Itoa(k))
+		strRef(ser.CreateElement("c:tx"), fmt.Sprintf("Sheet1!$%s$1", y), []string{s.Name})
+		if s.Color != nil {
+			spPr := ser.CreateElement("c:spPr")
+			if c.Type == LineChart || c.Type == ScatterChart {
+				spPr = spPr.CreateElement("a:ln")
+				spPr.CreateAttr("w", "28575")
+			}
+			spPr.CreateElement("a:solidFill").CreateElement("a:srgbClr").CreateAttr("val", rgb(s.Color))
+		}
+		if c.Type == BarChart || c.Type == ColumnChart {
+			val(ser, "c:invertIfNegative", "0")
+		}
+		yRef := fmt.Sprintf("Sheet1!$%s$2:$%s$%d", y, y, len(s.Values)+1)
+		if c.Type == ScatterChart {
+			numRef(ser.CreateElement("c:xVal"), fmt.Sprintf("Sheet1!$%s$2:$%s$%d", x, x, len(s.X)+1), s.X)
+			numRef(ser.CreateElement("c:yVal"), yRef, s.Values)
+		} else {
+			if len(c.Categories) > 0 {
+				strRef(ser.CreateElement("c:cat"), catRef, c.Categories)
+			}
+			numRef(ser.CreateElement("c:val"), yRef, s.Values)
+		}
+		if c.Type == LineChart || c.Type == ScatterChart {
+			val(ser, "c:smooth", "0")
+		}
+	}
+	switch c.Type {
+	case BarChart, ColumnChart:
+		val(g, "c:gapWidth", "150")
+	case LineChart:
+		val(g, "c:marker", "1")
+	case PieChart:
+		val(g, "c:firstSliceAng", "0")
+	}
+
+	// Axes.
+	if c.Type != PieChart {
+		val(g, "c:axId", "111111111")
+		val(g, "c:axId", "222222222")
+		xPos, yPos := "b", "l"
+		if c.Type == BarChart {
+			xPos, yPos = "l", "b"
+		}
+		xTag := "c:catAx"
+		if c.Type == ScatterChart {
+			xTag = "c:valAx"
+		}
+		axis(plot, xTag, "111111111", "222222222", xPos, c.XTitle, false)
+		axis(plot, "c:valAx", "222222222", "111111111", yPos, c.YTitle, true)
+	}
+	if c.Legend != "" {
+		legend := chart.CreateElement("c:legend")
+		val(legend, "c:legendPos", c.Legend)
+		val(legend, "c:overlay", "0")
+	}
+	val(chart, "c:plotVisOnly", "1")
+	ext := cs.CreateElement("c:externalData")
+	ext.CreateAttr("r:id", "rId1")
+	val(ext, "c:autoUpdate", "0")
+	return d, data, nil
+}
+
+// axis adds a category or value axis to the plot area.
+func axis(plot *etree.Element, tag, id, cross, pos, title string, grid bool) {
+	ax := plot.CreateElement(tag)
+	val(ax, "c:axId", id)
+	val(ax.CreateElement("c:scaling"), "c:orientation", "minMax")
+	val(ax, "c:delete", "0")
+	val(ax, "c:axPos", pos)
+	if grid {
+		ax.CreateElement("c:majorGridlines")
+	}
+	if title != "" {
+		chartTitle(ax, title)
+	}
+	if tag == "c:valAx" {
+		fmt := ax.CreateElement("c:numFmt")
+		fmt.CreateAttr("formatCode", "General")
+		fmt.CreateAttr("sourceLinked", "1")
+	}
+	val(ax, "c:tickLblPos", "nextTo")
+	val(ax, "c:crossAx", cross)
+	val(ax, "c:crosses", "autoZero")
+	if tag == "c:catAx" {
+		val(ax, "c:auto", "1")
+		val(ax, "c:lblAlgn", "ctr")
+		val(ax, "c:lblOffset", "100")
+	} else {
+		val(ax, "c:crossBetween", "between")
+	}
+}
+
+// chartTitle adds a title with rich text.
+func chartTitle(parent *etree.Element, s string) {
+	rich := parent.CreateElement("c:title").CreateElement("c:tx").CreateElement("c:rich")
+	rich.CreateElement("a:bodyPr")
+	rich.CreateElement("a:lstStyle")
+	rich.CreateElement("a:p").CreateElement("a:r").CreateElement("a:t").SetText(s)
+	val(parent.SelectElement("c:title"), "c:overlay", "0")
+}
+
+// strRef adds a string reference to the worksheet with cached values.
+func strRef(parent *etree.Element, f string, v []string) {
+	ref := parent.CreateElement("c:strRef")
+	ref.CreateElement("c:f").SetText(f)
+	cache := ref.CreateElement("c:strCache")
+	val(cache, "c:ptCount", strconv.Itoa(len(v)))
+	for i, s := range v {
+		pt := cache.CreateElement("c:pt")
+		pt.CreateAttr("idx", strconv.Itoa(i))
+		pt.CreateElement("c:v").SetText(s)
+	}
+}
+
+// numRef adds a number reference to the worksheet with cached values.
+func numRef(parent *etree.Element, f string, v []float64) {
+	ref := parent.CreateElement("c:numRef")
+	ref.CreateElement("c:f").SetText(f)
+	cache := ref.CreateElement("c:numCache")
+	cache.CreateElement("c:formatCode").SetText("General")
+	val(cache, "c:ptCount", strconv.Itoa(len(v)))
+	for i, x := range v {
+		pt := cache.CreateElement("c:pt")
+		pt.CreateAttr("idx", strconv.Itoa(i))
+		pt.CreateElement("c:v").SetText(strconv.FormatFloat(x, 'g', -1, 64))
+	}
+}
+
+// val adds a child element with a val attribute.
+func val(parent *etree.Element, tag, v string) *etree.Element {
+	e := parent.CreateElement(tag)
+	e.CreateAttr("val", v)
+	return e
+}
@@ -0,0 +1,261 @@
+package pptx
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// Connector geometries.
+const (
+	ConnectorStraight = "straightConnector1"
+	ConnectorElbow    = "bentConnector3"
+	ConnectorCurved   = "curvedConnector3"
+)
+
+// Arrowheads of a connector.
+const (
+	ArrowNone     = ""
+	ArrowTriangle = "triangle"
+	ArrowStealth  = "stealth"
+	ArrowOpen     = "arrow"
+	ArrowOval     = "oval"
+	ArrowDiamond  = "diamond"
+)
+
+// Site is the connection site of a shape, where a connector is glued to.
+// It is mapped to the index of the connection site of the shape's preset geometry.
+// Gluing is supported for ShapeRect, ShapeRoundRect, ShapeDiamond, ShapeEllipse
+// and elements without preset geometry, e.g. charts, which use the sites of a rectangle.
+type Site int
+
+const (
+	SiteAuto   Site = iota // The side facing the other end of the connector.
+	SiteTop                // Center of the top edge.
+	SiteLeft               // Center of the left edge.
+	SiteBottom             // Center of the bottom edge.
+	SiteRight              // Center of the right edge.
+)
+
+// A Connector is a line between two points or shapes.
+//
+// If From or To is the name of a shape on the slide, the connector is glued to it
+// and the end point is computed from the shape's position.
+// PowerPoint keeps glued connectors attached when the shape is moved.
+type Connector struct {
+	X1, Y1, X2, Y2   Dimension // Start and end point, if not glued.
+	Type             string    // ConnectorStraight (default), ConnectorElbow or ConnectorCurved.
+	Outline          Outline   // Line style. The default color is black.
+	Head, Tail       string    // Arrowheads at the start and end, e.g. ArrowTriangle.
+	From, To         string    // Names of the shapes to glue to.
+	FromSite, ToSite Site      // Connection sites of the glued shapes.
+}
+
+// addConnector adds a connector to the slide's xml tree.
+// Connectors are added after all other elements, so that they can refer to them.
+func (s *Slide) addConnector(c Connector) error {
+	spTree := s.xml.FindElement("p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return fmt.Errorf("Cannot find spTree")
+	}
+	find := func(name string) (ShapeInfo, error) {
+		if sh, ok := findShape(spTree, name); ok {
+			return sh, nil
+		}
+		return ShapeInfo{}, fmt.Errorf("connector: shape %q does not exist", name)
+	}
+	var from, to *ShapeInfo
+	if c.From != "" {
+		sh, err := find(c.From)
+		if err != nil {
+			return err
+		}
+		from = &sh
+	}
+	if c.To != "" {
+		sh, err := find(c.To)
+		if err != nil {
+			return err
+		}
+		to = &sh
+	}
+	// Glued ends face the center of the other end.
+	x1, y1, x2, y2 := int64(c.X1), int64(c.Y1), int64(c.X2), int64(c.Y2)
+	if from != nil {
+		x1, y1 = center(*from)
+	}
+	if to != nil {
+		x2, y2 = center(*to)
+	}
+	fromSite, toSite := c.FromSite, c.ToSite
+	if from != nil {
+		if fromSite == SiteAuto {
+			fromSite = facing(*from, x2, y2)
+		}
+		x1, y1 = sitePoint(*from, fromSite)
+	}
+	if to != nil {
+		if toSite == SiteAuto {
+			toSite = facing(*to, x1, y1)
+		}
+		x2, y2 = sitePoint(*to, toSite)
+	}
+
+	typ := c.Type
+	if typ == "" {
+		typ = ConnectorStraight
+	}
+	cxn := etree.NewElement("p:cxnSp")
+	nv := cxn.CreateElement("p:nvCxnSpPr")
+	cNvPr := nv.CreateElement("p:cNvPr")
+	id, name := s.ids.new("Connector", "")
+	cNvPr.CreateAttr("id", strconv.Itoa(id))
+	cNvPr.CreateAttr("name", name)
+	cNvCxnSpPr := nv.CreateElement("p:cNvCxnSpPr")
+	if from != nil {
+		idx, err := siteIndex(*from, fromSite)
+		if err != nil {
+			return err
+		}
+		st := cNvCxnSpPr.CreateElement("a:stCxn")
+		st.CreateAttr("id", strconv.Itoa(from.ID))
+		st.CreateAttr("idx", strconv.Itoa(idx))
+	}
+	if to != nil {
+		idx, err := siteIndex(*to, toSite)
+		if err != nil {
+			return err
+		}
+		end := cNvCxnSpPr.CreateElement("a:endCxn")
+		end.CreateAttr("id", strconv.Itoa(to.ID))
+		end.CreateAttr("idx", strconv.Itoa(idx))
+	}
+	nv.CreateElement("p:nvPr")
+
+	spPr := cxn.CreateElement("p:spPr")
+	xfrm := spPr.CreateElement("a:xfrm")
+	if x2 < x1 {
+		xfrm.CreateAttr("flipH", "1")
+		x1, x2 = x2, x1
+	}
+	if y2 < y1 {
+		xfrm.CreateAttr("flipV", "1")
+		y1, y2 = y2, y1
+	}
+	setPoint(xfrm.CreateElement("a:off"), "x", "y", Dimension(x1), Dimension(y1))
+	setPoint(xfrm.CreateElement("a:ext"), "cx", "cy", Dimension(x2-x1), Dimension(y2-y1))
+	prstGeom := spPr.CreateElement("a:prstGeom")
+	prstGeom.CreateAttr("prst", typ)
+	prstGeom.CreateElement("a:avLst")
+	o := c.Outline
+	if o.Color == nil {
+		o.Color = color.Black
+	}
+	ln := o.build()
+	if c.Head != ArrowNone {
+		ln.CreateElement("a:headEnd").CreateAttr("type", c.Head)
+	}
+	if c.Tail != ArrowNone {
+		ln.CreateElement("a:tailEnd").CreateAttr("type", c.Tail)
+	}
+	spPr.AddChild(ln)
+	spTree.AddChild(cxn)
+	return nil
+}
+
+// findShape returns the shape with the given name in slide coordinates.
+// Shapes within groups are transformed from the child coordinates of the group.
+func findShape(tree *etree.Element, name string) (ShapeInfo, bool) {
+	for _, e := range tree.ChildElements() {
+		if c := e.FindElement("./*/p:cNvPr"); c == nil || c.SelectAttrValue("name", "") != name {
+			continue
+		}
+		t := etree.NewElement("p:spTree")
+		t.AddChild(e.Copy())
+		if v := parseShapes(t, nil); len(v) > 0 {
+			return v[0], true
+		}
+	}
+	for _, grp := range tree.SelectElements("p:grpSp") {
+		sh, ok := findShape(grp, name)
+		if !ok {
+			continue
+		}
+		xfrm := grp.FindElement("p:grpSpPr/a:xfrm")
+		if xfrm == nil {
+			return sh, true
+		}
+		x, y := parsePoint(xfrm.SelectElement("a:off"), "x", "y")
+		w, h := parsePoint(xfrm.SelectElement("a:ext"), "cx", "cy")
+		cx, cy := parsePoint(xfrm.SelectElement("a:chOff"), "x", "y")
+		cw, ch := parsePoint(xfrm.SelectElement("a:chExt"), "cx", "cy")
+		scale := func(v, off, ext, chOff, chExt Dimension) Dimension {
+			if chExt == 0 {
+				return off
+			}
+			return off + Dimension(float64(v-chOff)*float64(ext)/float64(chExt))
+		}
+		sh.X, sh.W = scale(sh.X, x, w, cx, cw), scale(sh.X+sh.W, x, w, cx, cw)-scale(sh.X, x, w, cx, cw)
+		sh.Y, sh.H = scale(sh.Y, y, h, cy, ch), scale(sh.Y+sh.H, y, h, cy, ch)-scale(sh.Y, y, h, cy, ch)
+		return sh, true
+	}
+	return ShapeInfo{}, false
+}
+
+// center returns the center of a shape.
+func center(s ShapeInfo) (int64, int64) {
+	return int64(s.X + s.W/2), int64(s.Y + s.H/2)
+}
+
+// facing returns the site of the shape that faces the point x, y.
+func facing(s ShapeInfo, x, y int64) Site {
+	cx, cy := center(s)
+	dx, dy := x-cx, y-cy
+	if abs(dx) >= abs(dy) {
+		if dx > 0 {
+			return SiteRight
+		}
+		return SiteLeft
+	}
+	if dy > 0 {
+		return SiteBottom
+	}
+	return SiteTop
+}
+
+// siteIndex returns the index of the connection site in the preset geometry of the shape.
+// Rectangles have the sites top, left, bottom, right, an ellipse has 8 sites starting at the top.
+func siteIndex(s ShapeInfo, site Site) (int, error) {
+	switch s.geometry {
+	case "", ShapeRect, ShapeRoundRect, ShapeDiamond:
+		return int(site) - 1, nil
+	case ShapeEllipse:
+		return 2 * (int(site) - 1), nil
+	}
+	return 0, fmt.Errorf("connector: cannot glue to %q with geometry %q", s.Name, s.geometry)
+}
+
+// sitePoint returns the position of a connection site on the bounding box of a shape.
+func sitePoint(s ShapeInfo, site Site) (int64, int64) {
+	cx, cy := center(s)
+	switch site {
+	case SiteTop:
+		return cx, int64(s.Y)
+	case SiteLeft:
+		return int64(s.X), cy
+	case SiteBottom:
+		return cx, int64(s.Y + s.H)
+	case SiteRight:
+		return int64(s.X + s.W), cy
+	}
+	return cx, cy
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
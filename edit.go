@@ -0,0 +1,225 @@
+package pptx
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/beevik/etree"
+)
+
+// DeleteSlide removes the slide with index i (starting at 0) from the presentation.
+// The slide part, its relationships and content type are removed,
+// as well as media files which are not used by other parts.
+func (f *File) DeleteSlide(i int) error {
+	const presentationFile = "ppt/presentation.xml"
+	list, ids, parts, err := f.slideList()
+	if err != nil {
+		return err
+	}
+	if i < 0 || i >= len(ids) {
+		return fmt.Errorf("slide index out of range: %d", i)
+	}
+	name, rId, id := parts[i], ids[i].SelectAttrValue("r:id", ""), ids[i].SelectAttrValue("id", "")
+	list.RemoveChild(ids[i])
+
+	// Remove the slide from sections (p14:sectionLst in the extension list).
+	if x, err := f.xmlDoc(presentationFile); err != nil {
+		return err
+	} else {
+		for _, e := range x.FindElements("//p14:sldId[@id='" + id + "']") {
+			e.Parent().RemoveChild(e)
+		}
+	}
+
+	// Remove the relationship from the presentation.
+	x, err := f.xmlDoc(relsName(presentationFile))
+	if err != nil {
+		return err
+	}
+	for _, e := range x.FindElements("/Relationships/Relationship[@Id='" + rId + "']") {
+		e.Parent().RemoveChild(e)
+	}
+	if err := f.unlinkSlide(name); err != nil {
+		return err
+	}
+	return f.deleteUnreachable([]string{name})
+}
+
+// MoveSlide moves the slide at index from to index to.
+// Slides in between are shifted.
+func (f *File) MoveSlide(from, to int) error {
+	_, ids, _, err := f.slideList()
+	if err != nil {
+		return err
+	}
+	n := len(ids)
+	if from < 0 || from >= n || to < 0 || to >= n {
+		return fmt.Errorf("slide index out of range: %d -> %d", from, to)
+	}
+	perm := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if i != from {
+			perm = append(perm, i)
+		}
+	}
+	perm = append(perm[:to], append([]int{from}, perm[to:]...)...)
+	return f.Reorder(perm)
+}
+
+// Reorder changes the order of all slides.
+// The slide at the new position i is the slide at index perm[i] before the call.
+func (f *File) Reorder(perm []int) error {
+	list, ids, _, err := f.slideList()
+	if err != nil {
+		return err
+	}
+	if len(perm) != len(ids) {
+		return fmt.Errorf("permutation has %d entries for %d slides", len(perm), len(ids))
+	}
+	seen := make([]bool, len(ids))
+	for _, p := range perm {
+		if p < 0 || p >= len(ids) || seen[p] {
+			return fmt.Errorf("invalid slide permutation: %v", perm)
+		}
+		seen[p] = true
+	}
+	for _, e := range ids {
+		list.RemoveChild(e)
+	}
+	for _, p := range perm {
+		list.AddChild(ids[p])
+	}
+	return nil
+}
+
+// DuplicateSlide inserts a copy of the slide with index i after it and returns the index of the copy.
+// Media files are shared between both slides.
+func (f *File) DuplicateSlide(i int) (int, error) {
+	parts, err := f.slideParts()
+	if err != nil {
+		return -1, err
+	}
+	if i < 0 || i >= len(parts) {
+		return -1, fmt.Errorf("slide index out of range: %d", i)
+	}
+	src := parts[i]
+	x, err := f.xmlDoc(src)
+	if err != nil {
+		return -1, err
+	}
+	s := Slide{n: f.nextSlideNumber()}
+	s.name = fmt.Sprintf("slide%d.xml", s.n)
+	s.xml = x.Copy()
+
+	// Copy the relationships. The new slide is in the same directory,
+	// so relative targets stay the same.
+	if f.exists(relsName(src)) {
+		rels, err := f.xmlDoc(relsName(src))
+		if err != nil {
+			return -1, err
+		}
+		rels = rels.Copy()
+		for _, e := range rels.FindElements("/Relationships/Relationship") {
+			r := Relationship{Type: e.SelectAttrValue("Type", "")}
+			if ownedParts[r.Kind()] && e.SelectAttrValue("TargetMode", "") != "External" {
+				// Notes and charts belong to a single slide and are copied.
+				p, err := f.copyPart(resolveTarget(src, e.SelectAttrValue("Target", "")), "ppt/slides/"+s.name)
+				if err != nil {
+					return -1, err
+				}
+				e.CreateAttr("Target", relativeTarget("ppt/slides/"+s.name, p))
+			}
+		}
+		f.m["ppt/slides/_rels/"+s.name+".rels"] = rels
+	} else {
+		f.m["ppt/slides/_rels/"+s.name+".rels"] = emptyRels()
+	}
+
+	if err := f.addToContentTypes(s); err != nil {
+		return -1, err
+	}
+	if err := f.addToRelationships(&s); err != nil {
+		return -1, err
+	}
+	if err := f.addSlideFile(s); err != nil {
+		return -1, err
+	}
+	if err := f.addToPresentation(&s); err != nil {
+		return -1, err
+	}
+	if err := f.MoveSlide(len(parts), i+1); err != nil {
+		return -1, err
+	}
+	return i + 1, nil
+}
+
+// ownedParts are relationship kinds of parts that belong to a single slide or chart.
+// They are copied with a duplicated slide. Other targets, like layouts or media, are shared.
+var ownedParts = map[string]bool{
+	"notesSlide":      true,
+	"chart":           true,
+	"package":         true,
+	"chartUserShapes": true,
+	"themeOverride":   true,
+	"chartStyle":      true,
+	"chartColorStyle": true,
+}
+
+// copyPart copies a part owned by a new slide and returns the new part name.
+// Owned parts of the copy are copied recursively and a link back to the slide is retargeted.
+func (f *File) copyPart(name, slide string) (string, error) {
+	dst := f.freeName(name)
+	ct, override, err := f.contentType(name)
+	if err != nil {
+		return "", err
+	}
+	if path.Ext(name) == ".xml" {
+		x, err := f.xmlDoc(name)
+		if err != nil {
+			return "", err
+		}
+		f.m[dst] = x.Copy()
+	} else {
+		b, err := f.readFile(name)
+		if err != nil {
+			return "", err
+		}
+		f.m[dst] = rawFile(b)
+	}
+	if err := f.addContentType(dst, ct, override); err != nil {
+		return "", err
+	}
+	if !f.exists(relsName(name)) {
+		return dst, nil
+	}
+	rels, err := f.xmlDoc(relsName(name))
+	if err != nil {
+		return "", err
+	}
+	rels = rels.Copy()
+	for _, e := range rels.FindElements("/Relationships/Relationship") {
+		if e.SelectAttrValue("TargetMode", "") == "External" {
+			continue
+		}
+		target := resolveTarget(name, e.SelectAttrValue("Target", ""))
+		r := Relationship{Type: e.SelectAttrValue("Type", "")}
+		if r.Kind() == "slide" {
+			target = slide
+		} else if ownedParts[r.Kind()] {
+			if target, err = f.copyPart(target, slide); err != nil {
+				return "", err
+			}
+		}
+		e.CreateAttr("Target", relativeTarget(dst, target))
+	}
+	f.m[relsName(dst)] = rels
+	return dst, nil
+}
+
+// emptyRels returns a relationship file without entries.
+func emptyRels() *etree.Document {
+	d := etree.NewDocument()
+	d.CreateProcInst("xml", `version="1.0" encoding="UTF-8" standalone="yes"`)
+	d.CreateElement("Relationships").CreateAttr("xmlns", "http://schemas.openxmlformats.org/package/2006/relationships")
+	return d
+}
@@ -0,0 +1,119 @@
+package pptx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// A Group holds elements which are moved and scaled as one unit.
+//
+// The elements are positioned in the coordinates of the group (child coordinates).
+// The bounding box of the elements is mapped to X, Y, W, H on the slide or parent group.
+// If X, Y, W and H are all zero, the group is placed where the elements are.
+type Group struct {
+	X, Y, W, H Dimension
+	Name       string // Group name, default is "Group N".
+	TextBoxes  []TextBox
+	Images     []Image
+	Shapes     []Shape
+	Groups     []Group
+}
+
+// addGroup adds a group to the slide's xml tree.
+func (s *Slide) addGroup(f *File, g Group) error {
+	grp, err := s.buildGroup(f, g)
+	if err != nil {
+		return err
+	}
+	spTree := s.xml.FindElement("p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return fmt.Errorf("Cannot find spTree")
+	}
+	spTree.AddChild(grp)
+	return nil
+}
+
+// buildGroup returns the p:grpSp element of a group.
+// Images are added to the slide's relationships and parts.
+func (s *Slide) buildGroup(f *File, g Group) (*etree.Element, error) {
+	id, name := s.ids.new("Group", g.Name)
+	grp := etree.NewElement("p:grpSp")
+	nv := grp.CreateElement("p:nvGrpSpPr")
+	cNvPr := nv.CreateElement("p:cNvPr")
+	cNvPr.CreateAttr("id", strconv.Itoa(id))
+	cNvPr.CreateAttr("name", name)
+	nv.CreateElement("p:cNvGrpSpPr")
+	nv.CreateElement("p:nvPr")
+	xfrm := grp.CreateElement("p:grpSpPr").CreateElement("a:xfrm")
+
+	var children []*etree.Element
+	for _, tb := range g.TextBoxes {
+		if err := tb.build(s.ids.new("TextBox", "")); err != nil {
+			return nil, err
+		}
+		children = append(children, tb.xml.Root())
+	}
+	for _, im := range g.Images {
+		if err := im.layout(); err != nil {
+			return nil, err
+		}
+		media, err := s.addMedia(f, im.Extension, im.Data)
+		if err != nil {
+			return nil, err
+		}
+		x, err := im.build(s.addRel(relTypeImage, media, false), s.ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(im.Svg) > 0 {
+			if err := s.addSvg(f, x.Root(), im); err != nil {
+				return nil, err
+			}
+		}
+		children = append(children, x.Root())
+	}
+	for _, sh := range g.Shapes {
+		children = append(children, sh.build(s.ids.new("Shape", sh.Name)))
+	}
+	for _, sub := range g.Groups {
+		c, err := s.buildGroup(f, sub)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, c)
+	}
+
+	// The child extent is the bounding box of the elements.
+	var x0, y0, x1, y1 Dimension
+	for i, c := range children {
+		e := c.FindElement("p:spPr/a:xfrm")
+		if e == nil {
+			e = c.FindElement("p:grpSpPr/a:xfrm")
+		}
+		x, y := parsePoint(e.SelectElement("a:off"), "x", "y")
+		w, h := parsePoint(e.SelectElement("a:ext"), "cx", "cy")
+		if i == 0 || x < x0 {
+			x0 = x
+		}
+		if i == 0 || y < y0 {
+			y0 = y
+		}
+		if i == 0 || x+w > x1 {
+			x1 = x + w
+		}
+		if i == 0 || y+h > y1 {
+			y1 = y + h
+		}
+		grp.AddChild(c)
+	}
+	if g.X == 0 && g.Y == 0 && g.W == 0 && g.H == 0 {
+		g.X, g.Y, g.W, g.H = x0, y0, x1-x0, y1-y0
+	}
+	setPoint(xfrm.CreateElement("a:off"), "x", "y", g.X, g.Y)
+	setPoint(xfrm.CreateElement("a:ext"), "cx", "cy", g.W, g.H)
+	setPoint(xfrm.CreateElement("a:chOff"), "x", "y", x0, y0)
+	setPoint(xfrm.CreateElement("a:chExt"), "cx", "cy", x1-x0, y1-y0)
+	return grp, nil
+}
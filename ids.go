@@ -0,0 +1,50 @@
+package pptx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// shapeIds allocates the ids and names of shapes (p:cNvPr) on a slide.
+// All element kinds get their id from the allocator, which is seeded with
+// the shapes that are already on the slide.
+type shapeIds struct {
+	next  int
+	ids   map[int]bool
+	names map[string]bool
+}
+
+// newShapeIds returns an allocator for the shape tree of a slide.
+func newShapeIds(spTree *etree.Element) *shapeIds {
+	a := &shapeIds{next: 1, ids: make(map[int]bool), names: make(map[string]bool)}
+	if spTree == nil {
+		return a
+	}
+	for _, e := range spTree.FindElements(".//p:cNvPr") {
+		if id, err := strconv.Atoi(e.SelectAttrValue("id", "")); err == nil {
+			a.ids[id] = true
+		}
+		a.names[e.SelectAttrValue("name", "")] = true
+	}
+	return a
+}
+
+// new returns an unused id and a name for a shape.
+// If name is empty, it is generated from the kind, like PowerPoint does, e.g. "TextBox 3" for id 4.
+func (a *shapeIds) new(kind, name string) (int, string) {
+	for a.ids[a.next] {
+		a.next++
+	}
+	id := a.next
+	a.ids[id] = true
+	if name == "" {
+		name = fmt.Sprintf("%s %d", kind, id-1)
+		for n := id; a.names[name]; n++ {
+			name = fmt.Sprintf("%s %d", kind, n)
+		}
+	}
+	a.names[name] = true
+	return id, name
+}
@@ -2,10 +2,16 @@ package pptx
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"image"
+	_ "image/gif" // register the gif decoder for image.Decode/DecodeConfig
+	"image/jpeg"
 	"image/png"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/beevik/etree"
 )
@@ -20,26 +26,120 @@ type Image struct {
 	W, H      Dimension
 	Extension string
 	Data      []byte
+	Link      string     // Click action: a url, or a slide, e.g. "#3" or "#next".
+	Svg       []byte     // SVG data, see NewSvg. Data is the raster fallback.
+	Fit       string     // How the image fits into W and H: FitStretch (default), FitContain or FitCover.
+	Crop      Crop       // Cropping of the source image.
+	Rotation  float64    // Clockwise rotation in degrees.
+	FlipH     bool       // Flip horizontally.
+	FlipV     bool       // Flip vertically.
+	Geometry  string     // Preset geometry to clip the image, e.g. ShapeEllipse. Default is ShapeRect.
+	cover     [2]float64 // Horizontal and vertical fraction which is cropped on each side by FitCover.
 }
 
-// svg needs different handling.
-//func NewSvg(svg []byte, x, y, w, h Dimension) Image { return Image{x, y, w, h, "svg", svg} }
+// Crop removes edges of the source image, given in percent of the image size.
+type Crop struct {
+	Left, Top, Right, Bottom float64
+}
+
+// Fit modes of an Image.
+const (
+	FitStretch = ""        // The image fills W and H and may be distorted.
+	FitContain = "contain" // The image is scaled to fit into W and H and centered.
+	FitCover   = "cover"   // The image is scaled to cover W and H and cropped.
+)
 
 func NewImage(m image.Image, x, y, w, h Dimension) Image {
 	var b bytes.Buffer
 	png.Encode(&b, m)
-	return Image{x, y, w, h, "png", b.Bytes()}
+	return Image{X: x, Y: y, W: w, H: h, Extension: "png", Data: b.Bytes()}
+}
+func NewEmf(emf []byte, x, y, w, h Dimension) Image {
+	return Image{X: x, Y: y, W: w, H: h, Extension: "emf", Data: emf}
+}
+
+// imageTypes are the content types of image file extensions.
+var imageTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"jpg":  "image/jpeg",
+	"gif":  "image/gif",
+	"bmp":  "image/bmp",
+	"tiff": "image/tiff",
+	"tif":  "image/tiff",
+	"wmf":  "image/x-wmf",
+	"emf":  "image/x-emf",
+	"svg":  "image/svg+xml",
+}
+
+// NewImageData returns an image from encoded data, which is stored as it is.
+// The format is detected from the content: PNG, JPEG, GIF, BMP, TIFF, WMF or EMF.
+func NewImageData(data []byte, x, y, w, h Dimension) (Image, error) {
+	ext := imageFormat(data)
+	if ext == "" {
+		return Image{}, fmt.Errorf("unknown image format")
+	}
+	return Image{X: x, Y: y, W: w, H: h, Extension: ext, Data: data}, nil
+}
+
+// imageFormat returns the file extension for image data, or "" if it is unknown.
+func imageFormat(b []byte) string {
+	has := func(s string) bool { return bytes.HasPrefix(b, []byte(s)) }
+	switch {
+	case has("\x89PNG\r\n\x1a\n"):
+		return "png"
+	case has("\xff\xd8\xff"):
+		return "jpeg"
+	case has("GIF87a"), has("GIF89a"):
+		return "gif"
+	case has("BM") && len(b) > 14:
+		return "bmp"
+	case has("II*\x00"), has("MM\x00*"):
+		return "tiff"
+	case has("\xd7\xcd\xc6\x9a"), has("\x01\x00\x09\x00"), has("\x02\x00\x09\x00"):
+		return "wmf" // placeable, memory or disk metafile
+	case has("\x01\x00\x00\x00") && len(b) > 44 && string(b[40:44]) == " EMF":
+		return "emf"
+	}
+	return ""
+}
+
+// Jpeg returns a copy of the image, which is transcoded to JPEG with the given quality (1-100).
+// It can be used to reduce the size of photos. PNG, GIF and JPEG images can be transcoded.
+func (im Image) Jpeg(quality int) (Image, error) {
+	m, _, err := image.Decode(bytes.NewReader(im.Data))
+	if err != nil {
+		return im, fmt.Errorf("jpeg transcode: %s", err)
+	}
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, m, &jpeg.Options{Quality: quality}); err != nil {
+		return im, err
+	}
+	im.Extension, im.Data = "jpeg", b.Bytes()
+	return im, nil
 }
-func NewEmf(emf []byte, x, y, w, h Dimension) Image { return Image{x, y, w, h, "emf", emf} }
 
 // addImageRef adds the image reference to the the slide's xml tree.
 // The image reference is appended to the slide at the path:
 // <p:sld...><p:cSld><p:spTree>
-func (s *Slide) addImageRef(im Image, imageNum int) error {
-	xml, err := im.build(imageNum)
+func (s *Slide) addImageRef(f *File, im Image) error {
+	if err := im.layout(); err != nil {
+		return err
+	}
+	media, err := s.addMedia(f, im.Extension, im.Data)
 	if err != nil {
 		return err
 	}
+	rId := s.addRel(relTypeImage, media, false)
+	xml, err := im.build(rId, s.ids)
+	if err != nil {
+		return err
+	}
+	if len(im.Svg) > 0 {
+		if err := s.addSvg(f, xml.Root(), im); err != nil {
+			return err
+		}
+	}
 	root := s.xml.Root()
 	if root == nil {
 		return fmt.Errorf("Cannot find root element")
@@ -56,37 +156,75 @@ func (s *Slide) addImageRef(im Image, imageNum int) error {
 	return nil
 }
 
-// addImageFile adds the png file to the presentation.
-func (f *File) addImageFile(im Image, imageNum, slideNum int) error {
-	imagePath := fmt.Sprintf("ppt/media/slide%dimage%d.%s", slideNum, imageNum, im.Extension)
-	f.m[imagePath] = bytes.NewBuffer(im.Data)
-	return nil
+// addMedia returns the part name of a media file with the given content.
+// Media with the same content is stored once and shared by all slides, including media of the input package.
+// New media is added as a part of the slide, e.g. ppt/media/image3.png.
+func (s *Slide) addMedia(f *File, ext string, data []byte) (string, error) {
+	ct, ok := imageTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("mime type unknown for: %s", ext)
+	}
+	for _, p := range s.parts {
+		if b, ok := p.data.(rawFile); ok && strings.HasPrefix(p.name, "ppt/media/") && bytes.Equal(b, data) {
+			return p.name, nil
+		}
+	}
+	if name := f.findMedia(data); name != "" {
+		return name, nil
+	}
+	return s.addPart(f, "ppt/media/image1."+ext, rawFile(data), ct, false), nil
+}
+
+// findMedia returns the name of a media part with the given content, or an empty string.
+// The content hashes of the parts are cached.
+func (f *File) findMedia(data []byte) string {
+	if f.media == nil {
+		f.media = make(map[string][sha256.Size]byte)
+	}
+	sum := sha256.Sum256(data)
+	for _, name := range f.names() {
+		if !strings.HasPrefix(name, "ppt/media/") {
+			continue
+		}
+		h, ok := f.media[name]
+		if !ok {
+			b, err := f.readFile(name)
+			if err != nil {
+				continue
+			}
+			h = sha256.Sum256(b)
+			f.media[name] = h
+		}
+		if h == sum {
+			return name
+		}
+	}
+	return ""
 }
 
 // build create the xml tree of the image reference.
-func (im *Image) build(imNum int) (*etree.Document, error) {
-	//fmt.Println("pptx image build w/h/x/y", im.W, im.H, im.X, im.Y)
-	/*
-		cxDim := Dimension(im.W) * Inch / Dpi
-		cyDim := Dimension(im.H) * Inch / Dpi
-		x := strconv.FormatUint(uint64(im.X), 10)
-		y := strconv.FormatUint(uint64(im.Y), 10)
-		cx := strconv.FormatUint(uint64(cxDim), 10)
-		cy := strconv.FormatUint(uint64(cyDim), 10)
-	*/
+func (im *Image) build(rId string, ids *shapeIds) (*etree.Document, error) {
+	id, name := ids.new("Picture", "")
 	x := strconv.FormatUint(uint64(im.X), 10)
 	y := strconv.FormatUint(uint64(im.Y), 10)
 	cx := strconv.FormatUint(uint64(im.W), 10)
 	cy := strconv.FormatUint(uint64(im.H), 10)
+	if err := im.Crop.check(); err != nil {
+		return nil, err
+	}
+	geom := im.Geometry
+	if geom == "" {
+		geom = ShapeRect
+	}
 
 	template := `<p:pic>
 <p:nvPicPr>
-<p:cNvPr id="` + strconv.Itoa(1026+imNum) + `" name="Picture ` + strconv.Itoa(imNum+1) + `"/>
+<p:cNvPr id="` + strconv.Itoa(id) + `" name="` + escape(name) + `"/>
 <p:cNvPicPr/>
 <p:nvPr/>
 </p:nvPicPr>
 <p:blipFill>
-<a:blip r:embed="rId` + strconv.Itoa(imNum+2) + `">
+<a:blip r:embed="` + rId + `">
 <a:extLst>
 <a:ext uri="{28A0092B-C50C-407E-A947-70E740481C1C}">
 <a14:useLocalDpi xmlns:a14="http://schemas.microsoft.com/office/drawing/2010/main" val="0"/>
@@ -99,11 +237,11 @@ func (im *Image) build(imNum int) (*etree.Document, error) {
 </a:stretch>
 </p:blipFill>
 <p:spPr bwMode="auto">
-<a:xfrm>
+<a:xfrm` + im.xfrmAttrs() + `>
 <a:off x="` + x + `" y="` + y + `"/>
 <a:ext cx="` + cx + `" cy="` + cy + `"/>
 </a:xfrm>
-<a:prstGeom prst="rect">
+<a:prstGeom prst="` + geom + `">
 <a:avLst/>
 </a:prstGeom>
 <a:noFill/>
@@ -119,8 +257,19 @@ func (im *Image) build(imNum int) (*etree.Document, error) {
 </p:spPr>
 </p:pic>`
 	doc := etree.NewDocument()
-	err := doc.ReadFromString(template)
-	return doc, err
+	if err := doc.ReadFromString(template); err != nil {
+		return nil, err
+	}
+	if im.Link != "" {
+		hlinkClick(doc.FindElement("p:pic/p:nvPicPr/p:cNvPr"), im.Link)
+	}
+	src := doc.FindElement("p:pic/p:blipFill/a:srcRect")
+	for i, v := range im.srcRect() {
+		if v != 0 {
+			src.CreateAttr([]string{"l", "t", "r", "b"}[i], strconv.Itoa(v))
+		}
+	}
+	return doc, nil
 }
 
 /* This original image was 192x107
@@ -164,3 +313,161 @@ func (im *Image) build(imNum int) (*etree.Document, error) {
      </p:spPr>
    </p:pic>
 */
+
+// xfrmAttrs returns the rotation and flip attributes of the transformation.
+func (im *Image) xfrmAttrs() string {
+	s := ""
+	if im.Rotation != 0 {
+		s += ` rot="` + strconv.Itoa(int(math.Round(im.Rotation*60000))) + `"`
+	}
+	if im.FlipH {
+		s += ` flipH="1"`
+	}
+	if im.FlipV {
+		s += ` flipV="1"`
+	}
+	return s
+}
+
+// check returns an error, if nothing remains of the image.
+func (c Crop) check() error {
+	if c.Left+c.Right >= 100 || c.Top+c.Bottom >= 100 {
+		return fmt.Errorf("image is cropped completely: %+v", c)
+	}
+	return nil
+}
+
+// srcRect returns the cropping of the left, top, right and bottom edges in 1/1000 percent.
+// The crop of FitCover applies to the image after Crop.
+func (im *Image) srcRect() [4]int {
+	l, t, r, b := im.Crop.Left/100, im.Crop.Top/100, im.Crop.Right/100, im.Crop.Bottom/100
+	dx, dy := im.cover[0]*(1-l-r), im.cover[1]*(1-t-b)
+	v := [4]int{}
+	for i, f := range []float64{l + dx, t + dy, r + dx, b + dy} {
+		v[i] = int(math.Round(f * 100000))
+	}
+	return v
+}
+
+// layout sets the size and cropping of the image according to the fit mode.
+// If W and H are zero, the size is computed from the pixel size and resolution of the image.
+// If only one of them is zero, it is computed from the aspect ratio.
+func (im *Image) layout() error {
+	if err := im.Crop.check(); err != nil {
+		return err
+	}
+	if im.Fit != FitStretch && im.Fit != FitContain && im.Fit != FitCover {
+		return fmt.Errorf("unknown image fit: %q", im.Fit)
+	}
+	if im.W > 0 && im.H > 0 && im.Fit == FitStretch {
+		return nil
+	}
+	pw, ph, dpiX, dpiY, err := im.pixelSize()
+	if err != nil {
+		return fmt.Errorf("image size: %s", err)
+	}
+	// Natural size of the cropped image in EMU.
+	nw := float64(pw) / dpiX * float64(Inch) * (1 - (im.Crop.Left+im.Crop.Right)/100)
+	nh := float64(ph) / dpiY * float64(Inch) * (1 - (im.Crop.Top+im.Crop.Bottom)/100)
+	w, h := float64(im.W), float64(im.H)
+	switch {
+	case w == 0 && h == 0:
+		im.W, im.H = Dimension(nw), Dimension(nh)
+		return nil
+	case w == 0:
+		im.W = Dimension(h * nw / nh)
+		return nil
+	case h == 0:
+		im.H = Dimension(w * nh / nw)
+		return nil
+	}
+	switch im.Fit {
+	case FitContain:
+		scale := math.Min(w/nw, h/nh)
+		cw, ch := nw*scale, nh*scale
+		im.X += Dimension((w - cw) / 2)
+		im.Y += Dimension((h - ch) / 2)
+		im.W, im.H = Dimension(cw), Dimension(ch)
+	case FitCover:
+		scale := math.Max(w/nw, h/nh)
+		dx := (1 - w/(nw*scale)) / 2 // cropped fraction on each side
+		dy := (1 - h/(nh*scale)) / 2
+		im.cover = [2]float64{dx, dy}
+	}
+	return nil
+}
+
+// pixelSize returns the size of the image in pixels and the resolution in dots per inch.
+// The resolution is read from the PNG pHYs chunk, the JPEG JFIF header or the BMP header.
+// If it is not stored in the file, Dpi is used.
+func (im Image) pixelSize() (w, h int, dpiX, dpiY float64, err error) {
+	dpiX, dpiY = Dpi, Dpi
+	b := im.Data
+	switch im.Extension {
+	case "png", "jpeg", "jpg", "gif":
+		c, _, err := image.DecodeConfig(bytes.NewReader(b))
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		w, h = c.Width, c.Height
+	case "bmp":
+		if len(b) < 46 {
+			return 0, 0, 0, 0, fmt.Errorf("bmp header is too short")
+		}
+		le := func(i int) int32 { return int32(binary.LittleEndian.Uint32(b[i:])) }
+		w, h = int(le(18)), int(le(22))
+		if h < 0 {
+			h = -h // top-down bitmap
+		}
+		if x, y := le(38), le(42); x > 0 && y > 0 {
+			dpiX, dpiY = float64(x)*0.0254, float64(y)*0.0254 // pixels per meter
+		}
+		return w, h, dpiX, dpiY, nil
+	default:
+		return 0, 0, 0, 0, fmt.Errorf("unknown pixel size of %s image: W and H must be set", im.Extension)
+	}
+	if x, y := density(b); x > 0 && y > 0 {
+		dpiX, dpiY = x, y
+	}
+	return w, h, dpiX, dpiY, nil
+}
+
+// density returns the resolution in dots per inch stored in a PNG or JPEG file, or 0.
+func density(b []byte) (float64, float64) {
+	be16 := func(i int) float64 { return float64(binary.BigEndian.Uint16(b[i:])) }
+	be32 := func(i int) float64 { return float64(binary.BigEndian.Uint32(b[i:])) }
+	switch imageFormat(b) {
+	case "png":
+		// Chunks: length, type, data, crc.
+		for i := 8; i+8 <= len(b); {
+			n := int(binary.BigEndian.Uint32(b[i:]))
+			typ := string(b[i+4 : i+8])
+			if typ == "pHYs" && n == 9 && i+17 <= len(b) {
+				if b[i+16] != 1 { // unit is not meter
+					return 0, 0
+				}
+				return be32(i+8) * 0.0254, be32(i+12) * 0.0254
+			}
+			if typ == "IDAT" || n < 0 {
+				break
+			}
+			i += 12 + n
+		}
+	case "jpeg":
+		// Segments: 0xFF, marker, length including the length bytes.
+		for i := 2; i+4 <= len(b) && b[i] == 0xFF; i += 2 + int(be16(i+2)) {
+			if b[i+1] != 0xE0 || i+16 > len(b) || string(b[i+4:i+9]) != "JFIF\x00" {
+				continue
+			}
+			x, y := be16(i+12), be16(i+14)
+			switch b[i+11] {
+			case 1: // dots per inch
+				return x, y
+			case 2: // dots per cm
+				return x * 2.54, y * 2.54
+			}
+			return 0, 0
+		}
+	}
+	return 0, 0
+}
@@ -18,8 +18,9 @@ type ItemBox struct {
 
 // Item is a line of text with an indentation level.
 type Item struct {
-	Level int
-	Text  string
+	Level     int
+	Text      string
+	Paragraph Paragraph // Paragraph formatting of the item.
 }
 
 // SimpleItems splits a string at newlines into items.
@@ -46,8 +47,8 @@ func splitLevelText(s string) (int, string) {
 }
 
 // addItemBox adds an ItemBox to the slide's xml tree.
-func (s *Slide) addItemBox(ib ItemBox, ibNum int) error {
-	if err := ib.build(ibNum); err != nil {
+func (s *Slide) addItemBox(ib ItemBox) error {
+	if err := ib.build(s.ids.new("ItemBox", "")); err != nil {
 		return err
 	}
 	root := s.xml.Root()
@@ -68,25 +69,20 @@ func (s *Slide) addItemBox(ib ItemBox, ibNum int) error {
 }
 
 // build creates the xml tree of an item box.
-func (ib *ItemBox) build(ibNum int) error {
-	numStr := strconv.Itoa(ibNum + 1)
+func (ib *ItemBox) build(id int, name string) error {
 	x := strconv.FormatUint(uint64(ib.X), 10)
 	y := strconv.FormatUint(uint64(ib.Y), 10)
 	w := strconv.FormatUint(uint64(ib.Width), 10)
 	h := strconv.FormatUint(uint64(ib.Height), 10)
 	template := `<p:sp>
 <p:nvSpPr>
-<p:cNvPr id="` + strconv.Itoa(ibNum+2) + `" name="ItemBox ` + numStr + `"/>
+<p:cNvPr id="` + strconv.Itoa(id) + `" name="` + escape(name) + `"/>
 <p:cNvSpPr/>
 <p:nvPr>
 <p:ph idx="1"/>
 </p:nvPr>
 </p:nvSpPr>
 <p:spPr>
-<a:xfrm>
-<a:off x="` + x + `" y="` + y + `"/>
-<a:ext cx="` + w + `" cy="` + h + `"/>
-</a:xfrm>
 </p:spPr>
 <p:txBody>
 <a:bodyPr/>
@@ -97,6 +93,20 @@ func (ib *ItemBox) build(ibNum int) error {
 	if err := ib.xml.ReadFromString(template); err != nil {
 		return err
 	}
+	// Without position and size, the body placeholder of the layout is used.
+	if ib.X != 0 || ib.Y != 0 || ib.Width != 0 || ib.Height != 0 {
+		spPr := ib.xml.FindElement("p:sp/p:spPr")
+		if spPr == nil {
+			return fmt.Errorf("cannot find spPr")
+		}
+		xfrm := spPr.CreateElement("a:xfrm")
+		off := xfrm.CreateElement("a:off")
+		off.CreateAttr("x", x)
+		off.CreateAttr("y", y)
+		ext := xfrm.CreateElement("a:ext")
+		ext.CreateAttr("cx", w)
+		ext.CreateAttr("cy", h)
+	}
 	txBody := ib.xml.FindElement("p:sp/p:txBody")
 	if txBody == nil {
 		return fmt.Errorf("cannot find txBody")
@@ -116,5 +126,6 @@ func (ib ItemBox) buildLine(item Item) *etree.Document {
 	ar := ap.CreateElement("a:r")
 	at := ar.CreateElement("a:t")
 	at.CreateCharData(item.Text)
+	item.Paragraph.apply(ap)
 	return doc
 }
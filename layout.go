@@ -0,0 +1,104 @@
+package pptx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LayoutInfo describes a slide layout of the presentation.
+type LayoutInfo struct {
+	Name   string // Layout name (p:cSld name), e.g. "Title and Content".
+	Type   string // Layout type, e.g. "title", "obj", "twoObj" or "blank". Default is "cust".
+	Part   string // Part name, e.g. "ppt/slideLayouts/slideLayout2.xml".
+	Master string // Part name of the slide master.
+}
+
+// Layouts returns the slide layouts of all slide masters in the order of the master's layout lists.
+func (f *File) Layouts() ([]LayoutInfo, error) {
+	const presentationFile = "ppt/presentation.xml"
+	p, err := f.xmlDoc(presentationFile)
+	if err != nil {
+		return nil, err
+	}
+	var layouts []LayoutInfo
+	for _, m := range p.FindElements("/p:presentation/p:sldMasterIdLst/p:sldMasterId") {
+		master, err := f.relTarget(presentationFile, m.SelectAttrValue("r:id", ""))
+		if err != nil {
+			return nil, err
+		}
+		x, err := f.xmlDoc(master)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range x.FindElements("/p:sldMaster/p:sldLayoutIdLst/p:sldLayoutId") {
+			part, err := f.relTarget(master, l.SelectAttrValue("r:id", ""))
+			if err != nil {
+				return nil, err
+			}
+			y, err := f.xmlDoc(part)
+			if err != nil {
+				return nil, err
+			}
+			li := LayoutInfo{Part: part, Master: master}
+			if e := y.SelectElement("p:sldLayout"); e != nil {
+				li.Type = e.SelectAttrValue("type", "cust")
+			}
+			if e := y.FindElement("/p:sldLayout/p:cSld"); e != nil {
+				li.Name = e.SelectAttrValue("name", "")
+			}
+			layouts = append(layouts, li)
+		}
+	}
+	return layouts, nil
+}
+
+// relTarget returns the target of the relationship with the given id.
+func (f *File) relTarget(part, rId string) (string, error) {
+	rels, err := f.rels(part)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range rels {
+		if r.ID == rId {
+			return r.Target, nil
+		}
+	}
+	return "", fmt.Errorf("%s: relationship does not exist: %s", part, rId)
+}
+
+// selectLayout sets the part name of the slide layout.
+// Slide.Layout is matched first against the layout names and then against the layout types.
+// If it is empty, the layout file number is given by Slide.Master.
+func (f *File) selectLayout(s *Slide) error {
+	if s.Layout == "" {
+		if s.Master == 0 {
+			s.Master = 1
+		}
+		s.layout = fmt.Sprintf("ppt/slideLayouts/slideLayout%d.xml", s.Master)
+		if !f.exists(s.layout) {
+			return fmt.Errorf("slide layout does not exist: %s", s.layout)
+		}
+		return nil
+	}
+	layouts, err := f.Layouts()
+	if err != nil {
+		return err
+	}
+	for _, l := range layouts {
+		if l.Name == s.Layout {
+			s.layout = l.Part
+			return nil
+		}
+	}
+	for _, l := range layouts {
+		if l.Type == s.Layout {
+			s.layout = l.Part
+			return nil
+		}
+	}
+	names := make([]string, len(layouts))
+	for i, l := range layouts {
+		names[i] = fmt.Sprintf("%q (%s)", l.Name, l.Type)
+	}
+	return fmt.Errorf("slide layout does not exist: %q, available layouts are: %s", s.Layout, strings.Join(names, ", "))
+}
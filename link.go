@@ -0,0 +1,107 @@
+package pptx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// Links of text, shapes and images are urls, e.g. "https://example.com" or "mailto:a@example.com",
+// or refer to a slide of the presentation:
+//
+//	"#3"                      third slide, which must exist when the link is added
+//	"#next", "#previous"      next or previous slide in the slide show
+//	"#first", "#last"         first or last slide
+var slideJumps = map[string]string{
+	"#next":     "nextslide",
+	"#previous": "previousslide",
+	"#first":    "firstslide",
+	"#last":     "lastslide",
+}
+
+// hlinkClick adds a click action to a run or non-visual shape properties.
+// The link is stored in a temporary attribute, until it is resolved to a relationship by resolveLinks.
+func hlinkClick(parent *etree.Element, link string) {
+	e := parent.CreateElement("a:hlinkClick")
+	e.CreateAttr("r:id", "")
+	e.CreateAttr("link", link)
+}
+
+// resolveLinks adds relationships for all links of a slide or notes tree.
+// The relationship is added by rel, which returns the relationship id.
+// Slides are the part names of the slides in the presentation.
+func resolveLinks(tree *etree.Element, slides []string, rel func(typ, target string, external bool) (string, error)) error {
+	for _, e := range tree.FindElements("//a:hlinkClick[@link]") {
+		link := e.SelectAttrValue("link", "")
+		e.RemoveAttr("link")
+		if jump, ok := slideJumps[link]; ok {
+			e.CreateAttr("action", "ppaction://hlinkshowjump?jump="+jump)
+			continue
+		}
+		if strings.HasPrefix(link, "#") {
+			n, err := strconv.Atoi(link[1:])
+			if err != nil {
+				return fmt.Errorf("link %q: unknown slide action", link)
+			}
+			if n < 1 || n > len(slides) {
+				return fmt.Errorf("link %q: slide does not exist", link)
+			}
+			id, err := rel(relTypeSlide, slides[n-1], false)
+			if err != nil {
+				return err
+			}
+			e.CreateAttr("r:id", id)
+			e.CreateAttr("action", "ppaction://hlinksldjump")
+			continue
+		}
+		id, err := rel(relTypeHyperlink, link, true)
+		if err != nil {
+			return err
+		}
+		e.CreateAttr("r:id", id)
+	}
+	return nil
+}
+
+// unlinkSlide removes the slide relationships of all parts to a slide, and the click actions which use them.
+func (f *File) unlinkSlide(slide string) error {
+	for _, part := range f.names() {
+		rels, err := f.rels(part)
+		if err != nil {
+			return err
+		}
+		for _, r := range rels {
+			if r.Type != relTypeSlide || r.Target != slide {
+				continue
+			}
+			x, err := f.xmlDoc(relsName(part))
+			if err != nil {
+				return err
+			}
+			for _, e := range x.FindElements("/Relationships/Relationship[@Id='" + r.ID + "']") {
+				e.Parent().RemoveChild(e)
+			}
+			if strings.HasSuffix(part, ".xml") {
+				d, err := f.xmlDoc(part)
+				if err != nil {
+					return err
+				}
+				dropLinks(d, r.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// dropLinks removes click actions with the relationship id from an xml part.
+func dropLinks(w interface{}, id string) {
+	d, ok := w.(*etree.Document)
+	if !ok {
+		return
+	}
+	for _, e := range d.FindElements("//a:hlinkClick[@r:id='" + id + "']") {
+		e.Parent().RemoveChild(e)
+	}
+}
@@ -0,0 +1,419 @@
+package pptx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// ImportSlides copies the slides with the given indexes of src to the end of the presentation.
+// Slide layouts, masters, themes and media are copied with them and get new part names.
+// A slide master which is identical to one in the presentation is not copied,
+// slides use the existing master and it's identical layouts instead.
+func (f *File) ImportSlides(src *File, indices []int) error {
+	parts, err := src.slideParts()
+	if err != nil {
+		return err
+	}
+	if f.m == nil {
+		f.m = make(map[string]io.WriterTo)
+	}
+	im := importer{dst: f, src: src, memo: make(map[string]string), copied: make(map[string]bool)}
+	for _, i := range indices {
+		if i < 0 || i >= len(parts) {
+			return fmt.Errorf("slide index out of range: %d", i)
+		}
+	}
+	// The slide numbers are assigned first, so that links to slides which are imported later are kept.
+	nums := make([]int, len(indices))
+	for k, i := range indices {
+		nums[k] = f.nextSlideNumber()
+		if _, ok := im.memo[parts[i]]; !ok {
+			im.memo[parts[i]] = fmt.Sprintf("ppt/slides/slide%d.xml", nums[k])
+		}
+	}
+	for k, i := range indices {
+		if err := im.slide(parts[i], nums[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importer copies parts from src to dst.
+type importer struct {
+	dst, src *File
+	memo     map[string]string // Part names in src to part names in dst.
+	copied   map[string]bool   // Slide masters of src, which are copied with all their layouts.
+	id       int               // Last used slide master or layout id.
+}
+
+// slide imports a slide as slide number n and appends it to the slide list.
+func (im *importer) slide(name string, n int) error {
+	s := Slide{n: n}
+	s.name = fmt.Sprintf("slide%d.xml", s.n)
+	dst := "ppt/slides/" + s.name
+	if _, err := im.copy(name, dst); err != nil {
+		return err
+	}
+	if err := im.dst.addToRelationships(&s); err != nil {
+		return err
+	}
+	return im.dst.addToPresentation(&s)
+}
+
+// target returns the part name in dst for a relationship target in src.
+// It returns an empty string, if the relationship should be removed.
+func (im *importer) target(r Relationship) (string, error) {
+	if d, ok := im.memo[r.Target]; ok {
+		return d, nil
+	}
+	switch r.Kind() {
+	case "slideLayout":
+		return im.layout(r.Target)
+	case "slideMaster":
+		return im.master(r.Target)
+	case "notesMaster":
+		return im.dst.notesMaster()
+	case "slide":
+		// Links to slides which are not imported are dropped, imported slides are in memo.
+		return "", nil
+	default:
+		return im.copy(r.Target, im.dst.freeName(r.Target))
+	}
+}
+
+// copy copies a part and everything it refers to from src to dst.
+func (im *importer) copy(name, dst string) (string, error) {
+	ct, override, err := im.src.contentType(name)
+	if err != nil {
+		return "", err
+	}
+	if x, err := im.src.xmlDoc(name); err == nil {
+		im.dst.m[dst] = x.Copy()
+	} else if b, err := im.src.readFile(name); err != nil {
+		return "", err
+	} else {
+		im.dst.m[dst] = rawFile(b)
+	}
+	im.memo[name] = dst
+	if err := im.dst.addContentType(dst, ct, override); err != nil {
+		return "", err
+	}
+
+	if !im.src.exists(relsName(name)) {
+		return dst, nil
+	}
+	rels, err := im.src.xmlDoc(relsName(name))
+	if err != nil {
+		return "", err
+	}
+	rels = rels.Copy()
+	im.dst.m[relsName(dst)] = rels
+	for _, e := range rels.FindElements("/Relationships/Relationship") {
+		r := Relationship{
+			Type:     e.SelectAttrValue("Type", ""),
+			Target:   resolveTarget(name, e.SelectAttrValue("Target", "")),
+			External: e.SelectAttrValue("TargetMode", "") == "External",
+		}
+		if r.External {
+			continue
+		}
+		if t, err := im.target(r); err != nil {
+			return "", err
+		} else if t == "" {
+			e.Parent().RemoveChild(e)
+			dropLinks(im.dst.m[dst], e.SelectAttrValue("Id", ""))
+		} else {
+			e.CreateAttr("Target", relativeTarget(dst, t))
+		}
+	}
+	return dst, nil
+}
+
+// master returns the slide master in dst for a master in src.
+// It uses an identical master of dst or copies it with all layouts and its theme.
+func (im *importer) master(name string) (string, error) {
+	if d, ok := im.memo[name]; ok {
+		return d, nil
+	}
+	masters, err := im.dst.masters()
+	if err != nil {
+		return "", err
+	}
+	for _, m := range masters {
+		if same, err := im.equal(name, m, "theme"); err != nil {
+			return "", err
+		} else if same {
+			im.memo[name] = m
+			return m, nil
+		}
+	}
+
+	// Copy the master. It's layouts are copied with it.
+	im.copied[name] = true
+	dst, err := im.copy(name, im.dst.freeName(name))
+	if err != nil {
+		return "", err
+	}
+	x, err := im.dst.xmlDoc(dst)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range x.FindElements("/p:sldMaster/p:sldLayoutIdLst/p:sldLayoutId") {
+		if id, err := im.nextId(); err != nil {
+			return "", err
+		} else {
+			e.CreateAttr("id", id)
+		}
+	}
+
+	// Add the master to the presentation.
+	const presentationFile = "ppt/presentation.xml"
+	rId, err := im.dst.addRel(presentationFile, relTypeSlideMaster, dst)
+	if err != nil {
+		return "", err
+	}
+	p, err := im.dst.xmlDoc(presentationFile)
+	if err != nil {
+		return "", err
+	}
+	list := p.FindElement("/p:presentation/p:sldMasterIdLst")
+	if list == nil {
+		return "", fmt.Errorf("%s: Cannot find <p:sldMasterIdLst...", presentationFile)
+	}
+	id, err := im.nextId()
+	if err != nil {
+		return "", err
+	}
+	e := list.CreateElement("p:sldMasterId")
+	e.CreateAttr("id", id)
+	e.CreateAttr("r:id", rId)
+	return dst, nil
+}
+
+// layout returns the slide layout in dst for a layout in src.
+// If the master has been copied, the layout is copied with it.
+// Otherwise an identical layout of the master in dst is used, or it is added to that master.
+func (im *importer) layout(name string) (string, error) {
+	rels, err := im.src.rels(name)
+	if err != nil {
+		return "", err
+	}
+	master := ""
+	for _, r := range rels {
+		if r.Kind() == "slideMaster" {
+			master = r.Target
+		}
+	}
+	if master == "" {
+		return "", fmt.Errorf("%s: slide layout has no master", name)
+	}
+	dstMaster, err := im.master(master)
+	if err != nil {
+		return "", err
+	}
+	if d, ok := im.memo[name]; ok || im.copied[master] {
+		if !ok {
+			return im.copy(name, im.dst.freeName(name))
+		}
+		return d, nil
+	}
+
+	// Use an identical layout of the existing master.
+	masterRels, err := im.dst.rels(dstMaster)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range masterRels {
+		if r.Kind() != "slideLayout" {
+			continue
+		}
+		if same, err := im.equal(name, r.Target, ""); err != nil {
+			return "", err
+		} else if same {
+			im.memo[name] = r.Target
+			return r.Target, nil
+		}
+	}
+
+	// Add the layout to the existing master.
+	dst, err := im.copy(name, im.dst.freeName(name))
+	if err != nil {
+		return "", err
+	}
+	rId, err := im.dst.addRel(dstMaster, relTypeSlideLayout, dst)
+	if err != nil {
+		return "", err
+	}
+	x, err := im.dst.xmlDoc(dstMaster)
+	if err != nil {
+		return "", err
+	}
+	root := x.SelectElement("p:sldMaster")
+	if root == nil {
+		return "", fmt.Errorf("%s: Cannot find <p:sldMaster...", dstMaster)
+	}
+	list := root.SelectElement("p:sldLayoutIdLst")
+	if list == nil {
+		list = etree.NewElement("p:sldLayoutIdLst")
+		// The list follows p:clrMap.
+		if c := root.SelectElement("p:clrMap"); c != nil {
+			root.InsertChildAt(c.Index()+1, list)
+		} else {
+			root.AddChild(list)
+		}
+	}
+	id, err := im.nextId()
+	if err != nil {
+		return "", err
+	}
+	e := list.CreateElement("p:sldLayoutId")
+	e.CreateAttr("id", id)
+	e.CreateAttr("r:id", rId)
+	return dst, nil
+}
+
+// equal compares the xml of part a in src with part b in dst.
+// The list of layouts of a slide master is ignored.
+// Other relationships, e.g. to background images, must have the same ids and targets with the same content.
+// If kind is not empty, the targets of the relationship of that kind are also compared.
+func (im *importer) equal(a, b, kind string) (bool, error) {
+	str := func(f *File, part string) (string, error) {
+		x, err := f.xmlDoc(part)
+		if err != nil {
+			return "", err
+		}
+		if e := x.FindElement("/p:sldMaster/p:sldLayoutIdLst"); e != nil {
+			x = x.Copy()
+			e = x.FindElement("/p:sldMaster/p:sldLayoutIdLst")
+			e.Parent().RemoveChild(e)
+		}
+		return x.WriteToString()
+	}
+	if s, err := str(im.src, a); err != nil {
+		return false, err
+	} else if t, err := str(im.dst, b); err != nil {
+		return false, err
+	} else if s != t {
+		return false, nil
+	}
+	if same, err := im.sameRels(a, b, kind); err != nil || !same {
+		return false, err
+	}
+	if kind == "" {
+		return true, nil
+	}
+	target := func(f *File, part string) (string, error) {
+		rels, err := f.rels(part)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range rels {
+			if r.Kind() == kind {
+				return r.Target, nil
+			}
+		}
+		return "", fmt.Errorf("%s: has no %s relationship", part, kind)
+	}
+	if ta, err := target(im.src, a); err != nil {
+		return false, err
+	} else if tb, err := target(im.dst, b); err != nil {
+		return false, err
+	} else {
+		return im.equal(ta, tb, "")
+	}
+}
+
+// sameRels compares the relationships of part a in src with part b in dst.
+// Layouts, masters and relationships of the given kind are not compared.
+func (im *importer) sameRels(a, b, kind string) (bool, error) {
+	ra, err := im.src.rels(a)
+	if err != nil {
+		return false, err
+	}
+	rb, err := im.dst.rels(b)
+	if err != nil {
+		return false, err
+	}
+	ids := make(map[string]Relationship)
+	for _, r := range rb {
+		ids[r.ID] = r
+	}
+	for _, r := range ra {
+		if k := r.Kind(); k == "slideLayout" || k == "slideMaster" || k == kind {
+			continue
+		}
+		t, ok := ids[r.ID]
+		if !ok || t.Type != r.Type || t.External != r.External {
+			return false, nil
+		}
+		if r.External {
+			if r.Target != t.Target {
+				return false, nil
+			}
+			continue
+		}
+		x, err := im.src.readFile(r.Target)
+		if err != nil {
+			return false, err
+		}
+		y, err := im.dst.readFile(t.Target)
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(x, y) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// nextId returns an unused id for a slide master or layout.
+// They share the same id space, starting at 2147483648.
+func (im *importer) nextId() (string, error) {
+	if im.id == 0 {
+		im.id = 2147483647
+		p, err := im.dst.xmlDoc("ppt/presentation.xml")
+		if err != nil {
+			return "", err
+		}
+		ids := p.FindElements("/p:presentation/p:sldMasterIdLst/p:sldMasterId")
+		masters, err := im.dst.masters()
+		if err != nil {
+			return "", err
+		}
+		for _, m := range masters {
+			if x, err := im.dst.xmlDoc(m); err != nil {
+				return "", err
+			} else {
+				ids = append(ids, x.FindElements("/p:sldMaster/p:sldLayoutIdLst/p:sldLayoutId")...)
+			}
+		}
+		for _, e := range ids {
+			if n, err := strconv.Atoi(e.SelectAttrValue("id", "")); err == nil && n > im.id {
+				im.id = n
+			}
+		}
+	}
+	im.id++
+	return strconv.Itoa(im.id), nil
+}
+
+// masters returns the part names of all slide masters of the presentation.
+func (f *File) masters() ([]string, error) {
+	rels, err := f.rels("ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+	var v []string
+	for _, r := range rels {
+		if r.Kind() == "slideMaster" {
+			v = append(v, r.Target)
+		}
+	}
+	return v, nil
+}
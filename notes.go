@@ -0,0 +1,149 @@
+package pptx
+
+import (
+	"fmt"
+
+	"github.com/beevik/etree"
+)
+
+// addNotes adds a notes slide to a slide.
+// A default notes master is created, if the presentation does not have one.
+func (f *File) addNotes(slide string, lines []Line) error {
+	master, err := f.notesMaster()
+	if err != nil {
+		return err
+	}
+	name := f.freeName("ppt/notesSlides/notesSlide1.xml")
+	d := etree.NewDocument()
+	if err := d.ReadFromString(xmlHeader + `<p:notes ` + nsApr + `><p:cSld><p:spTree>` +
+		`<p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/>` +
+		`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Slide Image Placeholder 1"/><p:cNvSpPr><a:spLocks noGrp="1" noRot="1" noChangeAspect="1"/></p:cNvSpPr><p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr><p:spPr/></p:sp>` +
+		`<p:sp><p:nvSpPr><p:cNvPr id="3" name="Notes Placeholder 2"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:spPr/><p:txBody><a:bodyPr/><a:lstStyle/></p:txBody></p:sp>` +
+		`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:notes>`); err != nil {
+		return err
+	}
+	txBody := d.FindElement("//p:txBody")
+	var tb TextBox
+	for _, line := range lines {
+		txBody.AddChild(tb.buildLine(line).Root())
+	}
+	f.m[name] = d
+	slides, err := f.slideParts()
+	if err != nil {
+		return err
+	}
+	if err := resolveLinks(d.Root(), slides, func(typ, target string, external bool) (string, error) {
+		if external {
+			return f.addExternalRel(name, typ, target)
+		}
+		return f.addRel(name, typ, target)
+	}); err != nil {
+		return err
+	}
+	if err := f.addContentType(name, ctNotesSlide, true); err != nil {
+		return err
+	}
+	if _, err := f.addRel(name, relTypeNotesMaster, master); err != nil {
+		return err
+	}
+	if _, err := f.addRel(name, relTypeSlide, slide); err != nil {
+		return err
+	}
+	_, err = f.addRel(slide, relTypeNotesSlide, name)
+	return err
+}
+
+// notesMaster returns the part name of the notes master.
+// If it does not exist, it is created with it's own theme.
+func (f *File) notesMaster() (string, error) {
+	const presentationFile = "ppt/presentation.xml"
+	rels, err := f.rels(presentationFile)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range rels {
+		if r.Kind() == "notesMaster" {
+			return r.Target, nil
+		}
+	}
+
+	theme := f.freeName("ppt/theme/theme1.xml")
+	t := etree.NewDocument()
+	if err := t.ReadFromString(xmlHeader + newTheme); err != nil {
+		return "", err
+	}
+	f.m[theme] = t
+	if err := f.addContentType(theme, ctTheme, true); err != nil {
+		return "", err
+	}
+
+	name := f.freeName("ppt/notesMasters/notesMaster1.xml")
+	d := etree.NewDocument()
+	if err := d.ReadFromString(xmlHeader + newNotesMaster); err != nil {
+		return "", err
+	}
+	f.m[name] = d
+	if err := f.addContentType(name, ctNotesMaster, true); err != nil {
+		return "", err
+	}
+	if _, err := f.addRel(name, relTypeTheme, theme); err != nil {
+		return "", err
+	}
+
+	// Add the notes master to the presentation, following the slide master list.
+	rId, err := f.addRel(presentationFile, relTypeNotesMaster, name)
+	if err != nil {
+		return "", err
+	}
+	p, err := f.xmlDoc(presentationFile)
+	if err != nil {
+		return "", err
+	}
+	root := p.SelectElement("p:presentation")
+	if root == nil {
+		return "", fmt.Errorf("%s: Cannot find <p:presentation...", presentationFile)
+	}
+	list := root.SelectElement("p:notesMasterIdLst")
+	if list == nil {
+		list = etree.NewElement("p:notesMasterIdLst")
+		if e := root.SelectElement("p:sldMasterIdLst"); e != nil {
+			root.InsertChildAt(e.Index()+1, list)
+		} else {
+			root.InsertChildAt(0, list)
+		}
+	}
+	list.CreateElement("p:notesMasterId").CreateAttr("r:id", rId)
+	return name, nil
+}
+
+// slideNotes returns the text of the notes slide of a slide.
+func (f *File) slideNotes(rels []Relationship) ([]Line, error) {
+	for _, r := range rels {
+		if r.Kind() != "notesSlide" {
+			continue
+		}
+		x, err := f.xmlDoc(r.Target)
+		if err != nil {
+			return nil, err
+		}
+		for _, sp := range x.FindElements("/p:notes/p:cSld/p:spTree/p:sp") {
+			if ph := sp.FindElement("p:nvSpPr/p:nvPr/p:ph"); ph != nil && ph.SelectAttrValue("type", "") == "body" {
+				if txBody := sp.SelectElement("p:txBody"); txBody != nil {
+					return parseText(txBody), nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+const newNotesMaster = `<p:notesMaster ` + nsApr + `><p:cSld><p:bg><p:bgRef idx="1001"><a:schemeClr val="bg1"/></p:bgRef></p:bg><p:spTree>` +
+	`<p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="0" cy="0"/><a:chOff x="0" y="0"/><a:chExt cx="0" cy="0"/></a:xfrm></p:grpSpPr>` +
+	`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Slide Image Placeholder 1"/><p:cNvSpPr><a:spLocks noGrp="1" noRot="1" noChangeAspect="1"/></p:cNvSpPr><p:nvPr><p:ph type="sldImg" idx="2"/></p:nvPr></p:nvSpPr>` +
+	`<p:spPr><a:xfrm><a:off x="685800" y="685800"/><a:ext cx="5486400" cy="3086100"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom><a:noFill/><a:ln w="12700"><a:solidFill><a:prstClr val="black"/></a:solidFill></a:ln></p:spPr></p:sp>` +
+	`<p:sp><p:nvSpPr><p:cNvPr id="3" name="Notes Placeholder 2"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph type="body" sz="quarter" idx="3"/></p:nvPr></p:nvSpPr>` +
+	`<p:spPr><a:xfrm><a:off x="685800" y="4000500"/><a:ext cx="5486400" cy="4457700"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>` +
+	`<p:txBody><a:bodyPr vert="horz" lIns="91440" tIns="45720" rIns="91440" bIns="45720" rtlCol="0"/><a:lstStyle/><a:p><a:pPr lvl="0"/><a:endParaRPr lang="en-US"/></a:p></p:txBody></p:sp>` +
+	`</p:spTree></p:cSld>` +
+	`<p:clrMap bg1="lt1" tx1="dk1" bg2="lt2" tx2="dk2" accent1="accent1" accent2="accent2" accent3="accent3" accent4="accent4" accent5="accent5" accent6="accent6" hlink="hlink" folHlink="folHlink"/>` +
+	`<p:notesStyle><a:lvl1pPr marL="0" algn="l" defTabSz="914400" rtl="0" eaLnBrk="1" latinLnBrk="0" hangingPunct="1"><a:defRPr sz="1200" kern="1200"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill><a:latin typeface="+mn-lt"/><a:ea typeface="+mn-ea"/><a:cs typeface="+mn-cs"/></a:defRPr></a:lvl1pPr></p:notesStyle></p:notesMaster>`
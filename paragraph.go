@@ -0,0 +1,81 @@
+package pptx
+
+import (
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// Paragraph alignments.
+const (
+	AlignLeft    = "l"
+	AlignCenter  = "ctr"
+	AlignRight   = "r"
+	AlignJustify = "just"
+	AlignDecimal = "dec" // Only for tab stops.
+)
+
+// Paragraph holds the formatting of a line, see LineElement and Item.
+// The zero value uses the defaults of the master.
+type Paragraph struct {
+	Align       string    // AlignLeft, AlignCenter, AlignRight or AlignJustify.
+	SpaceBefore float64   // Space before the paragraph in points.
+	SpaceAfter  float64   // Space after the paragraph in points.
+	LineSpacing float64   // Line spacing as a multiple of single spacing, e.g. 1.5.
+	MarginLeft  Dimension // Left margin of the paragraph.
+	Indent      Offset    // Indentation of the first line relative to the left margin, negative for a hanging indent.
+	RTL         bool      // Right to left text direction.
+	Tabs        []Tab     // Tab stops.
+}
+
+// Tab is a tab stop of a paragraph.
+type Tab struct {
+	Pos   Dimension // Position from the left margin.
+	Align string    // AlignLeft (default), AlignCenter, AlignRight or AlignDecimal.
+}
+
+// apply adds the paragraph properties (a:pPr) to a paragraph (a:p).
+// An existing a:pPr, e.g. with the item level, is extended.
+func (p Paragraph) apply(ap *etree.Element) {
+	pPr := ap.SelectElement("a:pPr")
+	create := pPr == nil
+	if create {
+		pPr = etree.NewElement("a:pPr")
+	}
+	if p.MarginLeft != 0 {
+		pPr.CreateAttr("marL", strconv.FormatUint(uint64(p.MarginLeft), 10))
+	}
+	if p.Indent != 0 {
+		pPr.CreateAttr("indent", strconv.Itoa(int(p.Indent)))
+	}
+	if p.Align != "" {
+		pPr.CreateAttr("algn", p.Align)
+	}
+	if p.RTL {
+		pPr.CreateAttr("rtl", "1")
+	}
+	if p.LineSpacing != 0 {
+		pPr.CreateElement("a:lnSpc").CreateElement("a:spcPct").CreateAttr("val", strconv.Itoa(int(p.LineSpacing*100000)))
+	}
+	if p.SpaceBefore != 0 {
+		pPr.CreateElement("a:spcBef").CreateElement("a:spcPts").CreateAttr("val", strconv.Itoa(int(p.SpaceBefore*100)))
+	}
+	if p.SpaceAfter != 0 {
+		pPr.CreateElement("a:spcAft").CreateElement("a:spcPts").CreateAttr("val", strconv.Itoa(int(p.SpaceAfter*100)))
+	}
+	if len(p.Tabs) > 0 {
+		tabLst := pPr.CreateElement("a:tabLst")
+		for _, t := range p.Tabs {
+			tab := tabLst.CreateElement("a:tab")
+			tab.CreateAttr("pos", strconv.FormatUint(uint64(t.Pos), 10))
+			align := t.Align
+			if align == "" {
+				align = AlignLeft
+			}
+			tab.CreateAttr("algn", align)
+		}
+	}
+	if create && (len(pPr.Attr) > 0 || len(pPr.Child) > 0) {
+		ap.InsertChildAt(0, pPr)
+	}
+}
@@ -0,0 +1,411 @@
+package pptx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// Relationship is an entry in the relationship file of a package part.
+type Relationship struct {
+	ID       string // E.g. "rId2"
+	Type     string // Full relationship type uri.
+	Target   string // Part name of the target, e.g. "ppt/media/image1.png" or the url if External.
+	External bool   // TargetMode="External"
+}
+
+// Kind returns the last element of the relationship type, e.g. "image" or "slideLayout".
+func (r Relationship) Kind() string {
+	return path.Base(r.Type)
+}
+
+// rawFile is the content of a binary file such as an image.
+// Other than a bytes.Buffer, it can be written multiple times.
+type rawFile []byte
+
+func (b rawFile) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// relsName returns the name of the relationship file for a part, e.g.
+// ppt/slides/slide1.xml -> ppt/slides/_rels/slide1.xml.rels
+// The relationship file of the package itself ("") is _rels/.rels.
+func relsName(part string) string {
+	if part == "" {
+		return "_rels/.rels"
+	}
+	return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+}
+
+// exists returns if the part is in the package.
+func (f *File) exists(name string) bool {
+	if _, ok := f.m[name]; ok {
+		return true
+	} else if f.d[name] {
+		return false
+	}
+	for _, v := range f.r.File {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the names of all parts in the package.
+func (f *File) names() []string {
+	var v []string
+	for _, z := range f.r.File {
+		if _, ok := f.m[z.Name]; !ok && !f.d[z.Name] && !strings.HasSuffix(z.Name, "/") {
+			v = append(v, z.Name)
+		}
+	}
+	for name := range f.m {
+		v = append(v, name)
+	}
+	sort.Strings(v)
+	return v
+}
+
+// deletePart removes a part, its relationship file and its content type override.
+func (f *File) deletePart(name string) error {
+	delete(f.media, name)
+	for _, s := range []string{name, relsName(name)} {
+		delete(f.m, s)
+		if f.d == nil {
+			f.d = make(map[string]bool)
+		}
+		f.d[s] = true
+	}
+	x, err := f.xmlDoc("[Content_Types].xml")
+	if err != nil {
+		return err
+	}
+	for _, e := range x.FindElements("/Types/Override[@PartName='/" + name + "']") {
+		e.Parent().RemoveChild(e)
+	}
+	return nil
+}
+
+// reachable returns all parts that can be reached from the package relationships.
+// Slides are only reached from the presentation, not by links from other slides or notes.
+func (f *File) reachable() (map[string]bool, error) {
+	seen := make(map[string]bool)
+	todo := []string{""} // _rels/.rels is the relationship file of the package root.
+	for len(todo) > 0 {
+		part := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		rels, err := f.rels(part)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rels {
+			if r.Type == relTypeSlide && part != "ppt/presentation.xml" {
+				continue
+			}
+			if !r.External && !seen[r.Target] && f.exists(r.Target) {
+				seen[r.Target] = true
+				todo = append(todo, r.Target)
+			}
+		}
+	}
+	return seen, nil
+}
+
+// deleteUnreachable deletes the parts that are reachable from start,
+// but not anymore from the package root.
+func (f *File) deleteUnreachable(start []string) error {
+	all, err := f.reachable()
+	if err != nil {
+		return err
+	}
+	candidates := make(map[string]bool)
+	todo := append([]string{}, start...)
+	for len(todo) > 0 {
+		part := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		if candidates[part] || all[part] || !f.exists(part) {
+			continue
+		}
+		candidates[part] = true
+		rels, err := f.rels(part)
+		if err != nil {
+			return err
+		}
+		for _, r := range rels {
+			if !r.External {
+				todo = append(todo, r.Target)
+			}
+		}
+	}
+	for part := range candidates {
+		if err := f.deletePart(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// xmlDoc returns the xml tree of a part.
+// It is loaded into the map of changed files.
+func (f *File) xmlDoc(name string) (*etree.Document, error) {
+	if err := f.readXml(name); err != nil {
+		return nil, err
+	}
+	if x, ok := f.m[name].(*etree.Document); ok {
+		return x, nil
+	}
+	return nil, fmt.Errorf("%s: is not an xml file", name)
+}
+
+// readFile returns the content of a part, either from the map of changed files or from the input.
+func (f *File) readFile(name string) ([]byte, error) {
+	if w, ok := f.m[name]; ok {
+		var b bytes.Buffer
+		if _, err := w.WriteTo(&b); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	if f.d[name] {
+		return nil, fmt.Errorf("%s: file has been deleted.", name)
+	}
+	for _, v := range f.r.File {
+		if v.Name == name {
+			r, err := v.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return ioutil.ReadAll(r)
+		}
+	}
+	return nil, fmt.Errorf("%s: file does not exist in input pptx.", name)
+}
+
+// rels returns the relationships of a part.
+// Internal targets are resolved to part names.
+// If the part has no relationship file, it returns nil.
+func (f *File) rels(part string) ([]Relationship, error) {
+	name := relsName(part)
+	if !f.exists(name) {
+		return nil, nil
+	}
+	x, err := f.xmlDoc(name)
+	if err != nil {
+		return nil, err
+	}
+	var rels []Relationship
+	for _, e := range x.FindElements("/Relationships/Relationship") {
+		r := Relationship{
+			ID:       e.SelectAttrValue("Id", ""),
+			Type:     e.SelectAttrValue("Type", ""),
+			Target:   e.SelectAttrValue("Target", ""),
+			External: e.SelectAttrValue("TargetMode", "") == "External",
+		}
+		if !r.External {
+			r.Target = resolveTarget(part, r.Target)
+		}
+		rels = append(rels, r)
+	}
+	return rels, nil
+}
+
+// resolveTarget returns the part name of a relationship target relative to part.
+func resolveTarget(part, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return target[1:]
+	}
+	return path.Join(path.Dir(part), target)
+}
+
+// relativeTarget returns the relationship target of the part name to, as seen from part.
+func relativeTarget(part, to string) string {
+	from := strings.Split(path.Dir(part), "/")
+	dst := strings.Split(to, "/")
+	i := 0
+	for i < len(from) && i < len(dst)-1 && from[i] == dst[i] {
+		i++
+	}
+	return strings.Repeat("../", len(from)-i) + strings.Join(dst[i:], "/")
+}
+
+// slideParts returns the part names of all slides in the order of the slide id list.
+func (f *File) slideParts() ([]string, error) {
+	_, _, parts, err := f.slideList()
+	return parts, err
+}
+
+// slideList returns the slide id list of ppt/presentation.xml,
+// it's p:sldId elements and the slide part names they refer to.
+func (f *File) slideList() (*etree.Element, []*etree.Element, []string, error) {
+	const presentationFile = "ppt/presentation.xml"
+	x, err := f.xmlDoc(presentationFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rels, err := f.rels(presentationFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	targets := make(map[string]string)
+	for _, r := range rels {
+		targets[r.ID] = r.Target
+	}
+	list := x.FindElement("/p:presentation/p:sldIdLst")
+	if list == nil {
+		return nil, nil, nil, nil // The presentation has no slides.
+	}
+	ids := list.SelectElements("p:sldId")
+	slides := make([]string, len(ids))
+	for i, e := range ids {
+		rId := e.SelectAttrValue("r:id", "")
+		if t, ok := targets[rId]; !ok {
+			return nil, nil, nil, fmt.Errorf("%s: slide relationship does not exist: %s", presentationFile, rId)
+		} else {
+			slides[i] = t
+		}
+	}
+	return list, ids, slides, nil
+}
+
+// addRel adds a relationship from part to the part name target and returns the new relationship id.
+// The relationship file is created if it does not exist.
+func (f *File) addRel(part, typ, target string) (string, error) {
+	name := relsName(part)
+	if !f.exists(name) {
+		if f.m == nil {
+			f.m = make(map[string]io.WriterTo)
+		}
+		f.m[name] = emptyRels()
+	}
+	x, err := f.xmlDoc(name)
+	if err != nil {
+		return "", err
+	}
+	root := x.SelectElement("Relationships")
+	if root == nil {
+		return "", fmt.Errorf("%s: Cannot find <Relationships...", name)
+	}
+	ids := make(map[string]bool)
+	for _, e := range root.ChildElements() {
+		ids[e.SelectAttrValue("Id", "")] = true
+	}
+	id := ""
+	for i := len(ids) + 1; ; i++ {
+		if id = fmt.Sprintf("rId%d", i); !ids[id] {
+			break
+		}
+	}
+	e := root.CreateElement("Relationship")
+	e.CreateAttr("Id", id)
+	e.CreateAttr("Type", typ)
+	e.CreateAttr("Target", relativeTarget(part, target))
+	return id, nil
+}
+
+// addExternalRel adds a relationship with an external target, e.g. a url, to a part.
+func (f *File) addExternalRel(part, typ, target string) (string, error) {
+	id, err := f.addRel(part, typ, target)
+	if err != nil {
+		return "", err
+	}
+	x, err := f.xmlDoc(relsName(part))
+	if err != nil {
+		return "", err
+	}
+	e := x.FindElement("/Relationships/Relationship[@Id='" + id + "']")
+	e.CreateAttr("Target", target)
+	e.CreateAttr("TargetMode", "External")
+	return id, nil
+}
+
+// contentType returns the content type of a part.
+// Override is true, if it is not a default for the file extension.
+func (f *File) contentType(name string) (ct string, override bool, err error) {
+	x, err := f.xmlDoc("[Content_Types].xml")
+	if err != nil {
+		return "", false, err
+	}
+	if e := x.FindElement("/Types/Override[@PartName='/" + name + "']"); e != nil {
+		return e.SelectAttrValue("ContentType", ""), true, nil
+	}
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, e := range x.FindElements("/Types/Default") {
+		if strings.EqualFold(e.SelectAttrValue("Extension", ""), ext) {
+			return e.SelectAttrValue("ContentType", ""), false, nil
+		}
+	}
+	return "", false, fmt.Errorf("%s: unknown content type", name)
+}
+
+// addContentType registers the content type of a part.
+// If override is false, it is added as a default for the file extension, if it does not exist.
+func (f *File) addContentType(name, ct string, override bool) error {
+	x, err := f.xmlDoc("[Content_Types].xml")
+	if err != nil {
+		return err
+	}
+	types := x.SelectElement("Types")
+	if types == nil {
+		return fmt.Errorf("[Content_Types].xml: Element does not exist: <Types...")
+	}
+	if override {
+		e := types.CreateElement("Override")
+		e.CreateAttr("PartName", "/"+name)
+		e.CreateAttr("ContentType", ct)
+		return nil
+	}
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, e := range types.SelectElements("Default") {
+		if strings.EqualFold(e.SelectAttrValue("Extension", ""), ext) {
+			return nil
+		}
+	}
+	e := types.CreateElement("Default")
+	e.CreateAttr("Extension", ext)
+	e.CreateAttr("ContentType", ct)
+	return nil
+}
+
+// freeName returns a part name which does not exist, with the same directory and
+// extension as name and a number before the extension, e.g. ppt/slideLayouts/slideLayout3.xml.
+func (f *File) freeName(name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimRight(strings.TrimSuffix(name, ext), "0123456789")
+	for i := 1; ; i++ {
+		if s := fmt.Sprintf("%s%d%s", base, i, ext); !f.exists(s) {
+			return s
+		}
+	}
+}
+
+// Relationship types.
+const (
+	relTypeSlide       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
+	relTypeSlideMaster = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideMaster"
+	relTypeSlideLayout = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout"
+	relTypeNotesSlide  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide"
+	relTypeNotesMaster = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesMaster"
+	relTypeTheme       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme"
+	relTypeImage       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+	relTypeChart       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+	relTypePackage     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/package"
+	relTypeHyperlink   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+)
+
+// Content types.
+const (
+	ctNotesSlide  = "application/vnd.openxmlformats-officedocument.presentationml.notesSlide+xml"
+	ctNotesMaster = "application/vnd.openxmlformats-officedocument.presentationml.notesMaster+xml"
+	ctTheme       = "application/vnd.openxmlformats-officedocument.theme+xml"
+	ctChart       = "application/vnd.openxmlformats-officedocument.drawingml.chart+xml"
+	ctXlsx        = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
@@ -0,0 +1,126 @@
+package pptx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// A Placeholder fills a placeholder of the slide layout.
+// Without a position, geometry and text formatting are inherited from the layout.
+type Placeholder struct {
+	Type       string    // Placeholder type: "title", "ctrTitle", "subTitle", "body", "pic", "dt", "ftr", "sldNum", ...
+	Idx        int       // Placeholder index, if Type is empty or the layout has several placeholders of the type.
+	X, Y, W, H Dimension // Optional position and size, W and H are required if it is given.
+	Lines      []Line    // Text content.
+	Font       Font      // Can be unspecified for defaults.
+	Image      *Image    // Picture for a "pic" placeholder, it's position is ignored and Fit is not supported.
+}
+
+// layoutPlaceholder returns the type and index of the matching placeholder in the slide layout.
+// A placeholder without type has type "obj", which is also matched by "body".
+func (f *File) layoutPlaceholder(layout string, ph Placeholder) (string, int, error) {
+	x, err := f.xmlDoc(layout)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, e := range x.FindElements("//p:nvPr/p:ph") {
+		typ := e.SelectAttrValue("type", "")
+		idx, _ := strconv.Atoi(e.SelectAttrValue("idx", "0"))
+		if t := e.SelectAttrValue("type", "obj"); ph.Type != "" && ph.Type != t && !(ph.Type == "body" && t == "obj") {
+			continue
+		}
+		if (ph.Type == "" || ph.Idx != 0) && ph.Idx != idx {
+			continue
+		}
+		return typ, idx, nil
+	}
+	return "", 0, fmt.Errorf("%s: slide layout has no placeholder of type %q with index %d", layout, ph.Type, ph.Idx)
+}
+
+// addPlaceholder adds a placeholder to the slide's xml tree.
+func (s *Slide) addPlaceholder(f *File, ph Placeholder) error {
+	typ, idx, err := f.layoutPlaceholder(s.layout, ph)
+	if err != nil {
+		return err
+	}
+	// Item boxes and other placeholders of the slide may already use it.
+	for _, e := range s.xml.FindElements("//p:nvPr/p:ph") {
+		if i, _ := strconv.Atoi(e.SelectAttrValue("idx", "0")); i == idx && (idx != 0 || e.SelectAttrValue("type", "") == typ) {
+			return fmt.Errorf("placeholder with type %q and index %d is used twice on the slide", e.SelectAttrValue("type", "obj"), idx)
+		}
+	}
+	var root *etree.Element
+	if ph.Image != nil {
+		if ph.Image.Fit != FitStretch {
+			return fmt.Errorf("placeholder image: fit %q is not supported", ph.Image.Fit)
+		}
+		media, err := s.addMedia(f, ph.Image.Extension, ph.Image.Data)
+		if err != nil {
+			return err
+		}
+		d, err := ph.Image.build(s.addRel(relTypeImage, media, false), s.ids)
+		if err != nil {
+			return err
+		}
+		if len(ph.Image.Svg) > 0 {
+			if err := s.addSvg(f, d.Root(), *ph.Image); err != nil {
+				return err
+			}
+		}
+		root = d.Root()
+		// The picture takes the position of the placeholder, rotation and flip are kept.
+		if xfrm := root.FindElement("p:spPr/a:xfrm"); len(xfrm.Attr) == 0 {
+			xfrm.Parent().RemoveChild(xfrm)
+		} else {
+			xfrm.RemoveChild(xfrm.SelectElement("a:off"))
+			xfrm.RemoveChild(xfrm.SelectElement("a:ext"))
+		}
+	} else {
+		root = etree.NewElement("p:sp")
+		nv := root.CreateElement("p:nvSpPr")
+		id, name := s.ids.new("Placeholder", "")
+		c := nv.CreateElement("p:cNvPr")
+		c.CreateAttr("id", strconv.Itoa(id))
+		c.CreateAttr("name", name)
+		nv.CreateElement("p:cNvSpPr").CreateElement("a:spLocks").CreateAttr("noGrp", "1")
+		nv.CreateElement("p:nvPr")
+		spPr := root.CreateElement("p:spPr")
+		if ph.W != 0 || ph.H != 0 || ph.X != 0 || ph.Y != 0 {
+			if ph.W == 0 || ph.H == 0 {
+				return fmt.Errorf("placeholder with type %q and index %d: position without size", ph.Type, ph.Idx)
+			}
+			xfrm := spPr.CreateElement("a:xfrm")
+			setPoint(xfrm.CreateElement("a:off"), "x", "y", ph.X, ph.Y)
+			setPoint(xfrm.CreateElement("a:ext"), "cx", "cy", ph.W, ph.H)
+		}
+		txBody := root.CreateElement("p:txBody")
+		txBody.CreateElement("a:bodyPr")
+		txBody.CreateElement("a:lstStyle")
+		tb := TextBox{Font: ph.Font}
+		for _, line := range ph.Lines {
+			txBody.AddChild(tb.buildLine(line).Root())
+		}
+		if len(ph.Lines) == 0 {
+			txBody.CreateElement("a:p")
+		}
+	}
+	nvPr := root.FindElement("./*/p:nvPr")
+	if nvPr == nil {
+		return fmt.Errorf("cannot find p:nvPr")
+	}
+	e := nvPr.CreateElement("p:ph")
+	if typ != "" {
+		e.CreateAttr("type", typ)
+	}
+	if idx != 0 {
+		e.CreateAttr("idx", strconv.Itoa(idx))
+	}
+	spTree := s.xml.FindElement("p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return fmt.Errorf("Cannot find spTree")
+	}
+	spTree.AddChild(root)
+	return nil
+}
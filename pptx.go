@@ -2,15 +2,20 @@ package pptx
 
 import (
 	"archive/zip"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	// "github.com/beevik/etree"
 )
 
 // Dimension is a EMU (english metric unit) used to position elements on a slide.
 type Dimension uint
 
+// Offset is a signed distance in EMU, e.g. a negative indentation.
+type Offset int
+
 const MilliMeter Dimension = 36000
 const Inch Dimension = 914400
 const Dpi = 96 // Dpi is used to calculate the size of images.
@@ -22,15 +27,12 @@ const Dpi = 96 // Dpi is used to calculate the size of images.
 type File struct {
 	fileName  string // filename
 	tmpName   string // temporary file name
-	r         *zip.ReadCloser
+	r         *zip.Reader
+	c         io.Closer              // Underlying input file, nil for in-memory packages.
 	m         map[string]io.WriterTo // Map of changed or new files.
+	d         map[string]bool        // Deleted files of the input.
 	numSlides int
-}
-
-type dummyReadCloser zip.ReadCloser
-
-func (z dummyReadCloser) Close() error {
-	return nil
+	media     map[string][sha256.Size]byte // Content hashes of media parts.
 }
 
 // Open unzips a pptx file, and stores the content in file.
@@ -42,11 +44,22 @@ func Open(filename string) (File, error) {
 	if r, err := zip.OpenReader(filename); err != nil {
 		return f, err
 	} else {
-		f.r = r
+		f.r = &r.Reader
+		f.c = r
 		return f, nil
 	}
 }
 
+// OpenReader reads a pptx file from r, which has the given size.
+// The file can be written with WriteTo or SaveAs.
+func OpenReader(r io.ReaderAt, size int64) (File, error) {
+	if zr, err := zip.NewReader(r, size); err != nil {
+		return File{}, err
+	} else {
+		return File{r: zr}, nil
+	}
+}
+
 // Abort closes the input file without writing anything.
 func (f File) Abort() {
 	f.closeInput()
@@ -55,50 +68,13 @@ func (f File) Abort() {
 // Close writes to the tempfile, closes the original file
 // and moves the new (temp file) over the original file.
 func (f File) Close() error {
+	if f.fileName == "" {
+		return fmt.Errorf("presentation has no file name: use SaveAs or WriteTo")
+	}
 	if out, err := os.Create(f.tmpName); err != nil {
 		return fmt.Errorf("Could not write to temporary file: %s", err)
 	} else {
-
-		// Create the new temporary zip file.
-		zw := zip.NewWriter(out)
-
-		// Write all files, which have not been modified or added.
-		for _, v := range f.r.File {
-			if _, ok := f.m[v.Name]; !ok {
-				if w, err := zw.Create(v.Name); err != nil {
-					zw.Close()
-					return err
-				} else {
-					if rc, err := v.Open(); err != nil {
-						zw.Close()
-					} else {
-						if _, err := io.Copy(w, rc); err != nil {
-							rc.Close()
-							zw.Close()
-							return err
-						} else {
-							rc.Close()
-						}
-					}
-				}
-			}
-		}
-
-		// Write all new files.
-		for name, v := range f.m {
-			if w, err := zw.Create(name); err != nil {
-				zw.Close()
-				return err
-			} else {
-				if _, err := v.WriteTo(w); err != nil {
-					zw.Close()
-					return err
-				}
-			}
-		}
-
-		// Close the zip writer.
-		if err := zw.Close(); err != nil {
+		if err := f.write(out); err != nil {
 			out.Close()
 			return err
 		}
@@ -118,9 +94,107 @@ func (f File) Close() error {
 	return nil
 }
 
+// SaveAs writes the presentation to a new file.
+// The original file is not modified and stays open,
+// call Abort to release it if the File is not used anymore.
+func (f File) SaveAs(path string) error {
+	tmpName := path + "_"
+	if out, err := os.Create(tmpName); err != nil {
+		return fmt.Errorf("Could not write to temporary file: %s", err)
+	} else {
+		if err := f.write(out); err != nil {
+			out.Close()
+			os.Remove(tmpName)
+			return err
+		}
+		if err := out.Close(); err != nil {
+			os.Remove(tmpName)
+			return err
+		}
+	}
+	// Rename the temp file, this also works if path is the original file.
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("Could not move %s to %s: %s", tmpName, path, err)
+	}
+	return nil
+}
+
+// WriteTo writes the presentation as a zip archive to w.
+// It implements io.WriterTo.
+func (f File) WriteTo(w io.Writer) (int64, error) {
+	cw := countWriter{w: w}
+	err := f.write(&cw)
+	return cw.n, err
+}
+
+// countWriter counts the bytes written.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// write writes the zip archive with all original and changed files to w.
+func (f File) write(out io.Writer) error {
+	// Create the new zip file.
+	zw := zip.NewWriter(out)
+
+	// Write all files, which have not been modified, added or deleted.
+	for _, v := range f.r.File {
+		if _, ok := f.m[v.Name]; !ok && !f.d[v.Name] {
+			if w, err := zw.Create(v.Name); err != nil {
+				zw.Close()
+				return err
+			} else {
+				if rc, err := v.Open(); err != nil {
+					zw.Close()
+					return err
+				} else {
+					if _, err := io.Copy(w, rc); err != nil {
+						rc.Close()
+						zw.Close()
+						return err
+					} else {
+						rc.Close()
+					}
+				}
+			}
+		}
+	}
+
+	// Write all new files in a stable order.
+	names := make([]string, 0, len(f.m))
+	for name := range f.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if w, err := zw.Create(name); err != nil {
+			zw.Close()
+			return err
+		} else {
+			if _, err := f.m[name].WriteTo(w); err != nil {
+				zw.Close()
+				return err
+			}
+		}
+	}
+
+	// Close the zip writer.
+	return zw.Close()
+}
+
 // closeInput closes the original input pptx file.
 func (f File) closeInput() error {
-	if err := f.r.Close(); err != nil {
+	if f.c == nil {
+		return nil
+	}
+	if err := f.c.Close(); err != nil {
 		return fmt.Errorf("Could not close the original pptx file: %s", err)
 	}
 	return nil
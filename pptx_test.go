@@ -1,12 +1,24 @@
 package pptx
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"image"
 	"image/color"
 	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
 	"io"
+	"io/ioutil"
+	"math"
 	"os"
+	"path"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/beevik/etree"
@@ -51,6 +63,97 @@ func (f File) printPath(filePath string) error {
 	return fmt.Errorf("file %s does not exist", filePath)
 }
 
+// checkPackage verifies that all xml parts of a written pptx file are well formed,
+// that all internal relationship targets exist and that each part has a content type.
+func checkPackage(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string][]byte)
+	for _, v := range r.File {
+		if strings.HasSuffix(v.Name, "/") {
+			continue // directory entry
+		}
+		rc, err := v.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := parts[v.Name]; ok {
+			t.Fatalf("duplicate part: %s", v.Name)
+		}
+		parts[v.Name] = b
+	}
+	docs := make(map[string]*etree.Document)
+	for name, b := range parts {
+		if strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".rels") {
+			d := etree.NewDocument()
+			if err := d.ReadFromBytes(b); err != nil {
+				t.Fatalf("%s: %s", name, err)
+			}
+			docs[name] = d
+		}
+	}
+	ct, ok := docs["[Content_Types].xml"]
+	if !ok {
+		t.Fatal("missing [Content_Types].xml")
+	}
+	defaults, overrides := make(map[string]bool), make(map[string]bool)
+	for _, e := range ct.FindElements("/Types/Default") {
+		defaults[e.SelectAttrValue("Extension", "")] = true
+	}
+	for _, e := range ct.FindElements("/Types/Override") {
+		overrides[e.SelectAttrValue("PartName", "")] = true
+	}
+	for name := range parts {
+		if name == "[Content_Types].xml" || overrides["/"+name] {
+			continue
+		}
+		if !defaults[strings.TrimPrefix(path.Ext(name), ".")] {
+			t.Errorf("%s: no content type", name)
+		}
+	}
+	// Slide master and layout ids share the same id space.
+	ids := make(map[string]bool)
+	for _, d := range docs {
+		for _, e := range append(d.FindElements("//p:sldMasterId"), d.FindElements("//p:sldLayoutId")...) {
+			id := e.SelectAttrValue("id", "")
+			if ids[id] {
+				t.Errorf("duplicate master or layout id: %s", id)
+			}
+			ids[id] = true
+		}
+	}
+	for name, d := range docs {
+		if !strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		// ppt/slides/_rels/slide1.xml.rels is relative to ppt/slides.
+		dir := path.Dir(path.Dir(name))
+		for _, e := range d.FindElements("/Relationships/Relationship") {
+			if e.SelectAttrValue("TargetMode", "") == "External" {
+				continue
+			}
+			target := e.SelectAttrValue("Target", "")
+			if strings.HasPrefix(target, "/") {
+				target = target[1:]
+			} else {
+				target = path.Join(dir, target)
+			}
+			if _, ok := parts[target]; !ok {
+				t.Errorf("%s: target does not exist: %s", name, target)
+			}
+		}
+	}
+	return parts
+}
+
 func greyImage() image.Image {
 	w, h := 500, 300
 	im := image.NewGray(image.Rect(0, 0, w, h))
@@ -118,3 +221,1605 @@ func exampleSlide(n int) Slide {
 		},
 	}
 }
+
+func TestNew(t *testing.T) {
+	f, err := New(Options{Title: "Test & Title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		s := exampleSlide(i)
+		s.Master = i
+		if err := f.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	parts := checkPackage(t, b.Bytes())
+	for i := 1; i <= 6; i++ {
+		if _, ok := parts[fmt.Sprintf("ppt/slideLayouts/slideLayout%d.xml", i)]; !ok {
+			t.Fatalf("layout %d is missing", i)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		if _, ok := parts[fmt.Sprintf("ppt/slides/slide%d.xml", i)]; !ok {
+			t.Fatalf("slide %d is missing", i)
+		}
+	}
+	if !bytes.Contains(parts["docProps/core.xml"], []byte("Test &amp; Title")) {
+		t.Fatal("title is not set")
+	}
+}
+
+func TestOpenReader(t *testing.T) {
+	data, err := ioutil.ReadFile("minimal.pptx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(exampleSlide(1)); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	n, err := f.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != int64(b.Len()) {
+		t.Fatalf("WriteTo returned %d, wrote %d bytes", n, b.Len())
+	}
+	parts := checkPackage(t, b.Bytes())
+	if _, ok := parts["ppt/slides/slide1.xml"]; !ok {
+		t.Fatal("slide is missing")
+	}
+	if err := f.Close(); err == nil {
+		t.Fatal("Close should fail without a file name")
+	}
+
+	// SaveAs writes a new file and leaves the template untouched.
+	dir, err := ioutil.TempDir("", "pptx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	g, err := Open("minimal.pptx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Abort()
+	if err := g.Add(exampleSlide(1)); err != nil {
+		t.Fatal(err)
+	}
+	out := dir + "/out.pptx"
+	if err := g.SaveAs(out); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := ioutil.ReadFile(out); err != nil {
+		t.Fatal(err)
+	} else {
+		checkPackage(t, saved)
+	}
+	if orig, err := ioutil.ReadFile("minimal.pptx"); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(orig, data) {
+		t.Fatal("SaveAs modified the template")
+	}
+}
+
+func TestSlides(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := f.Add(exampleSlide(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	g, err := OpenReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slides, err := g.Slides()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(slides))
+	}
+	s := slides[1]
+	if s.Name != "ppt/slides/slide2.xml" || s.Layout != "ppt/slideLayouts/slideLayout1.xml" {
+		t.Fatalf("wrong slide or layout name: %s %s", s.Name, s.Layout)
+	}
+	if len(s.TextBoxes) != 3 || len(s.Images) != 1 || len(s.Shapes) != 4 {
+		t.Fatalf("expected 3 text boxes, 1 image and 4 shapes, got %d %d %d", len(s.TextBoxes), len(s.Images), len(s.Shapes))
+	}
+	tb := s.TextBoxes[0]
+	if !tb.Title || tb.X != 30*MilliMeter || tb.Lines[0][0].Text != "Slide 2: alpha beta gamma" {
+		t.Fatalf("wrong title: %+v", tb)
+	}
+	if tb := s.TextBoxes[2]; len(tb.Lines) != 2 || tb.Font.Name != "Courier New" || tb.Font.Size != 22 {
+		t.Fatalf("wrong text box: %+v", tb)
+	}
+	im := s.Images[0]
+	if im.Extension != "png" || im.W != 130*MilliMeter || len(im.Data) == 0 {
+		t.Fatalf("wrong image: %s %d %d", im.Extension, im.W, len(im.Data))
+	}
+}
+
+// slideTitles returns the first text of each slide.
+func slideTitles(t *testing.T, f *File) []string {
+	t.Helper()
+	slides, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := make([]string, len(slides))
+	for i, s := range slides {
+		v[i] = s.TextBoxes[0].Lines[0][0].Text
+	}
+	return v
+}
+
+func TestDeleteMoveSlide(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := f.Add(exampleSlide(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.DeleteSlide(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.DeleteSlide(2); err == nil {
+		t.Fatal("expected index out of range")
+	}
+	if err := f.Add(exampleSlide(4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.MoveSlide(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(slideTitles(t, &f)); got != "[Slide 4: alpha beta gamma Slide 1: alpha beta gamma Slide 3: alpha beta gamma]" {
+		t.Fatalf("wrong slide order: %s", got)
+	}
+	if err := f.Reorder([]int{0, 0, 1}); err == nil {
+		t.Fatal("expected invalid permutation")
+	}
+	if err := f.Reorder([]int{1, 2, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(slideTitles(t, &f)); got != "[Slide 1: alpha beta gamma Slide 3: alpha beta gamma Slide 4: alpha beta gamma]" {
+		t.Fatalf("wrong slide order: %s", got)
+	}
+
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	parts := checkPackage(t, b.Bytes())
+	for _, name := range []string{"ppt/slides/slide2.xml", "ppt/slides/_rels/slide2.xml.rels"} {
+		if _, ok := parts[name]; ok {
+			t.Fatalf("deleted part is still present: %s", name)
+		}
+	}
+	if _, ok := parts["ppt/slides/slide4.xml"]; !ok {
+		t.Fatal("slide 4 is missing")
+	}
+	if bytes.Contains(parts["[Content_Types].xml"], []byte("slide2.xml")) {
+		t.Fatal("content type of deleted slide is still present")
+	}
+}
+
+func TestDuplicateSlide(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := f.Add(exampleSlide(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if i, err := f.DuplicateSlide(0); err != nil {
+		t.Fatal(err)
+	} else if i != 1 {
+		t.Fatalf("expected index 1, got %d", i)
+	}
+	if got := fmt.Sprint(slideTitles(t, &f)); got != "[Slide 1: alpha beta gamma Slide 1: alpha beta gamma Slide 2: alpha beta gamma]" {
+		t.Fatalf("wrong slide order: %s", got)
+	}
+	if f.m["ppt/slides/slide1.xml"] == f.m["ppt/slides/slide3.xml"] {
+		t.Fatal("slide xml is not copied")
+	}
+
+	// The media file is shared and survives deleting the original slide.
+	if err := f.DeleteSlide(0); err != nil {
+		t.Fatal(err)
+	}
+	slides, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media := slides[0].Shapes[3].Media; media != "ppt/media/image1.png" {
+		t.Fatalf("media is not shared: %s", media)
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestImportSlides(t *testing.T) {
+	src, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		s := exampleSlide(i)
+		s.Master = i
+		if err := src.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func(f *File, prefix string) int {
+		n := 0
+		for _, name := range f.names() {
+			if strings.HasPrefix(name, prefix) {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Import into a presentation with a different master.
+	dst, err := Open("minimal.pptx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Abort()
+	if err := dst.Add(exampleSlide(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.ImportSlides(&src, []int{1, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(slideTitles(t, &dst)); got != "[Slide 0: alpha beta gamma Slide 2: alpha beta gamma Slide 1: alpha beta gamma]" {
+		t.Fatalf("wrong slides: %s", got)
+	}
+	if n := count(&dst, "ppt/slideMasters/slideMaster"); n != 2 {
+		t.Fatalf("expected 2 masters, got %d", n)
+	}
+	if n := count(&dst, "ppt/slideLayouts/slideLayout"); n != 7 {
+		t.Fatalf("expected 7 layouts, got %d", n)
+	}
+	var b bytes.Buffer
+	if _, err := dst.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+
+	// Import into a presentation with the same master, which is not copied.
+	dst, err = New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := dst.ImportSlides(&src, []int{0, 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count(&dst, "ppt/slideMasters/slideMaster"); n != 1 {
+		t.Fatalf("expected 1 master, got %d", n)
+	}
+	if n := count(&dst, "ppt/slideLayouts/slideLayout"); n != 6 {
+		t.Fatalf("expected 6 layouts, got %d", n)
+	}
+	slides, err := dst.Slides()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(slides) != 4 || slides[3].Layout != "ppt/slideLayouts/slideLayout2.xml" {
+		t.Fatalf("wrong slides: %d", len(slides))
+	}
+	b.Reset()
+	if _, err := dst.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+
+	// A layout with the same xml, but a different background image is copied.
+	background := func(f *File, data []byte) {
+		const layout = "ppt/slideLayouts/slideLayout2.xml"
+		if _, err := f.xmlDoc(layout); err != nil {
+			t.Fatal(err)
+		}
+		f.m["ppt/media/background.png"] = rawFile(data)
+		if _, err := f.addRel(layout, relTypeImage, "ppt/media/background.png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	background(&src, []byte("source"))
+	for _, data := range []string{"source", "other"} {
+		dst, err = New(Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		background(&dst, []byte(data))
+		if err := dst.ImportSlides(&src, []int{1}); err != nil {
+			t.Fatal(err)
+		}
+		slides, err := dst.Slides()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reused := slides[0].Layout == "ppt/slideLayouts/slideLayout2.xml"; reused != (data == "source") {
+			t.Fatalf("%s: wrong layout %s", data, slides[0].Layout)
+		}
+	}
+}
+
+func TestReplaceText(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	s := Slide{
+		TextBoxes: []TextBox{
+			TextBox{Lines: []Line{
+				Line{{Text: "Hello {{cus", Color: red}, {Text: "tomer}}, revenue {{rev"}, {Text: "enue}}!"}},
+				Line{{Text: "{{date}}"}, {Text: " {{date"}, {Text: "}"}},
+			}, Font: Font{Size: 20}},
+		},
+	}
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ReplaceText(map[string]string{"{{customer}}": "ACME", "{{revenue}}": "{{date}}", "{{date}}": "today"}); err != nil {
+		t.Fatal(err)
+	}
+	slides, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := slides[0].TextBoxes[0].Lines
+	if len(lines[0]) != 3 || lines[0][0].Text != "Hello ACME" || lines[0][1].Text != ", revenue {{date}}" || lines[0][2].Text != "!" {
+		t.Fatalf("wrong first line: %+v", lines[0])
+	}
+	if lines[0][0].Color == nil || lines[0][1].Color != nil {
+		t.Fatal("text after a key must keep the formatting of its run")
+	}
+	if got := lines[1][0].Text + "|" + lines[1][1].Text + "|" + lines[1][2].Text; got != "today| {{date|}" {
+		t.Fatalf("wrong second line: %s", got)
+	}
+}
+
+func TestLayouts(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layouts, err := f.Layouts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) != 6 || layouts[1].Name != "Title and Content" || layouts[1].Type != "obj" || layouts[1].Part != "ppt/slideLayouts/slideLayout2.xml" {
+		t.Fatalf("wrong layouts: %+v", layouts)
+	}
+	for _, l := range []string{"Two Content", "blank"} {
+		if err := f.Add(Slide{Layout: l}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Add(Slide{Layout: "Comparison"}); err == nil || !strings.Contains(err.Error(), `"Title Only" (titleOnly)`) {
+		t.Fatalf("expected an error with the available layouts: %v", err)
+	}
+	if err := f.Add(Slide{Master: 7}); err == nil {
+		t.Fatal("expected an error for a missing layout file")
+	}
+	slides, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slides) != 2 || slides[0].Layout != "ppt/slideLayouts/slideLayout4.xml" || slides[1].Layout != "ppt/slideLayouts/slideLayout6.xml" {
+		t.Fatalf("wrong slide layouts: %+v", slides)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Slide{
+		Layout: "Title and Content",
+		Placeholders: []Placeholder{
+			{Type: "title", Lines: SimpleLines("Title")},
+			{Type: "body", Lines: SimpleLines("first\nsecond")},
+			{Type: "sldNum", X: 10 * MilliMeter, Y: 10 * MilliMeter, W: 20 * MilliMeter, H: 10 * MilliMeter},
+		},
+	}
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	// The item box uses the body placeholder with index 1.
+	s.ItemBoxes = []ItemBox{{Items: SimpleItems("a\n-b")}}
+	if err := f.Add(s); err == nil {
+		t.Fatal("expected an error for a placeholder used twice")
+	}
+	if err := f.Add(Slide{Layout: "Title and Content", Placeholders: []Placeholder{{Type: "title"}, {Type: "title"}}}); err == nil {
+		t.Fatal("expected an error for a placeholder used twice")
+	}
+	if err := f.Add(Slide{Layout: "obj", Placeholders: []Placeholder{{Type: "pic"}}}); err == nil {
+		t.Fatal("expected an error for a missing placeholder")
+	}
+	if err := f.Add(Slide{Layout: "obj", Placeholders: []Placeholder{{Type: "title", X: Inch, Y: Inch}}}); err == nil {
+		t.Fatal("expected an error for a placeholder position without size")
+	}
+	if err := f.Add(Slide{Layout: "twoObj", Placeholders: []Placeholder{{Idx: 2, Image: &Image{Extension: "png", Data: []byte{0}, Rotation: 90, FlipV: true}}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{Layout: "twoObj", Placeholders: []Placeholder{{Idx: 2, Image: &Image{Extension: "png", Data: []byte{0}, Fit: FitCover}}}}); err == nil {
+		t.Fatal("expected an error for a placeholder image with fit")
+	}
+	slides, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v []string
+	for _, sh := range slides[0].Shapes {
+		v = append(v, fmt.Sprintf("%s:%d:%d", sh.Placeholder, sh.PlaceholderIdx, sh.X))
+	}
+	// Placeholders without position inherit it from the layout.
+	if got := strings.Join(v, " "); got != "title:0:0 obj:1:0 sldNum:12:360000" {
+		t.Fatalf("wrong placeholders: %s", got)
+	}
+	if sh := slides[1].Shapes[0]; sh.Kind != "pic" || sh.PlaceholderIdx != 2 || sh.Media == "" {
+		t.Fatalf("wrong picture placeholder: %+v", sh)
+	}
+	// The picture keeps rotation and flip, the position comes from the layout.
+	parts, err := f.slideParts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xfrm := x.FindElement("//p:pic/p:spPr/a:xfrm"); xfrm == nil || xfrm.SelectAttrValue("rot", "") != "5400000" || xfrm.SelectAttrValue("flipV", "") != "1" || len(xfrm.ChildElements()) != 0 {
+		t.Fatal("wrong transformation of the picture placeholder")
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestNotes(t *testing.T) {
+	f, err := Open("minimal.pptx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Abort()
+	s := exampleSlide(1)
+	s.Notes = SimpleLines("first point\nsecond point")
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	s = exampleSlide(2)
+	s.Notes = SimpleLines("notes of slide 2")
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.DuplicateSlide(0); err != nil {
+		t.Fatal(err)
+	}
+	src, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.ImportSlides(&f, []int{2, 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range []*File{&f, &src} {
+		var b bytes.Buffer
+		if _, err := g.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		parts := checkPackage(t, b.Bytes())
+		n := 0
+		for name := range parts {
+			if strings.HasPrefix(name, "ppt/notesMasters/notesMaster") {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Fatalf("expected 1 notes master, got %d", n)
+		}
+		h, err := OpenReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		slides, err := h.Slides()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v []string
+		for _, s := range slides {
+			v = append(v, fmt.Sprintf("%d %s", len(s.Notes), s.Notes[len(s.Notes)-1][0].Text))
+		}
+		want := "[2 second point 2 second point 1 notes of slide 2]"
+		if g == &src {
+			want = "[1 notes of slide 2 2 second point]"
+		}
+		if got := fmt.Sprint(v); got != want {
+			t.Fatalf("wrong notes: %s", got)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tab := NewTable([][]string{
+		{"Name", "Q1", "Q2"},
+		{"alpha", "1", "2"},
+		{"beta", "3"},
+	}, 20*MilliMeter, 40*MilliMeter, 150*MilliMeter, 30*MilliMeter)
+	tab.Cells[0][1].GridSpan = 2
+	tab.Cells[1][0].RowSpan = 2
+	tab.Cells[2][2].Fill = color.RGBA{255, 0, 0, 255}
+	tab.Cells[2][2].Bottom = Border{Width: 12700, Color: color.Black}
+	if err := f.Add(Slide{Tables: []Table{tab}}); err != nil {
+		t.Fatal(err)
+	}
+	bad := tab
+	bad.Cells = [][]Cell{{{GridSpan: 4}}}
+	if err := f.Add(Slide{Tables: []Table{bad}}); err == nil {
+		t.Fatal("expected an error for a cell exceeding the table")
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(x.FindElements("//a:tc")); n != 9 {
+		t.Fatalf("expected 9 cells, got %d", n)
+	}
+	if n := len(x.FindElements("//a:tc[@hMerge='1']")); n != 1 {
+		t.Fatalf("expected 1 hMerge, got %d", n)
+	}
+	if n := len(x.FindElements("//a:tc[@vMerge='1']")); n != 1 {
+		t.Fatalf("expected 1 vMerge, got %d", n)
+	}
+	if e := x.FindElement("//a:tc/a:tcPr/a:lnB[@w='12700']"); e == nil {
+		t.Fatal("missing border")
+	}
+	if e := x.FindElement("//p:graphicFrame/p:xfrm/a:ext"); e == nil || e.SelectAttrValue("cx", "") != "5400000" {
+		t.Fatal("wrong table width")
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestChart(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cats := []string{"Q1", "Q2", "Q3"}
+	series := []Series{
+		{Name: "alpha", Values: []float64{1, 2.5, 3}, X: []float64{0, 1, 2}, Color: color.RGBA{255, 0, 0, 255}},
+		{Name: "beta", Values: []float64{3, 2, 1}, X: []float64{0, 1, 2}},
+	}
+	var charts []Chart
+	for _, typ := range []ChartType{BarChart, ColumnChart, LineChart, ScatterChart, PieChart, AreaChart} {
+		charts = append(charts, Chart{
+			W: 100 * MilliMeter, H: 60 * MilliMeter,
+			Type: typ, Title: string(typ), Categories: cats, Series: series,
+			XTitle: "x", YTitle: "y", Legend: "r",
+		})
+	}
+	if err := f.Add(Slide{Charts: charts}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{Charts: []Chart{{Type: "radar", Series: series}}}); err == nil {
+		t.Fatal("expected an error for an unknown chart type")
+	}
+	if _, err := f.DuplicateSlide(0); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files := checkPackage(t, b.Bytes())
+	for i := 1; i <= 12; i++ {
+		name := fmt.Sprintf("ppt/charts/chart%d.xml", i)
+		if _, ok := files[name]; !ok {
+			t.Fatalf("%s is missing", name)
+		}
+		xlsx := files[fmt.Sprintf("ppt/embeddings/Microsoft_Excel_Worksheet%d.xlsx", i)]
+		z, err := zip.NewReader(bytes.NewReader(xlsx), int64(len(xlsx)))
+		if err != nil {
+			t.Fatalf("embedded workbook %d: %s", i, err)
+		}
+		for _, zf := range z.File {
+			r, err := zf.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := etree.NewDocument().ReadFrom(r); err != nil {
+				t.Fatalf("workbook %d: %s: %s", i, zf.Name, err)
+			}
+			r.Close()
+		}
+	}
+	x := etree.NewDocument()
+	if err := x.ReadFromBytes(files["ppt/charts/chart4.xml"]); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(x.FindElements("//c:scatterChart/c:ser/c:xVal")); n != 2 {
+		t.Fatalf("expected 2 scatter series with x values, got %d", n)
+	}
+	if n := len(x.FindElements("//c:valAx")); n != 2 {
+		t.Fatalf("expected 2 value axes, got %d", n)
+	}
+	rels := etree.NewDocument()
+	if err := rels.ReadFromBytes(files["ppt/charts/_rels/chart7.xml.rels"]); err != nil {
+		t.Fatal(err)
+	}
+	if e := rels.FindElement("//Relationship"); e == nil || e.SelectAttrValue("Target", "") != "../embeddings/Microsoft_Excel_Worksheet7.xlsx" {
+		t.Fatal("duplicated chart must use a copy of the workbook")
+	}
+}
+
+func TestShape(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shapes := []Shape{
+		{X: 10 * MilliMeter, Y: 10 * MilliMeter, W: 40 * MilliMeter, H: 20 * MilliMeter},
+		{
+			X: 60 * MilliMeter, Y: 10 * MilliMeter, W: 40 * MilliMeter, H: 20 * MilliMeter,
+			Geometry: ShapeRoundRect, Fill: color.RGBA{0, 128, 0, 255},
+			Outline: Outline{Color: color.Black, Width: 25400, Dash: DashDash},
+			Lines:   SimpleLines("OK"), Anchor: "b",
+		},
+	}
+	if err := f.Add(Slide{Shapes: shapes}); err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	geoms := x.FindElements("//p:sp/p:spPr/a:prstGeom")
+	if len(geoms) != 2 || geoms[0].SelectAttrValue("prst", "") != "rect" || geoms[1].SelectAttrValue("prst", "") != "roundRect" {
+		t.Fatal("wrong preset geometries")
+	}
+	if e := x.FindElement("//a:ln[@w='25400']/a:prstDash[@val='dash']"); e == nil {
+		t.Fatal("missing dashed outline")
+	}
+	if e := x.FindElement("//p:txBody/a:bodyPr[@anchor='b']"); e == nil {
+		t.Fatal("missing text anchor")
+	}
+	infos, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sh := infos[0].Shapes; len(sh) != 2 || sh[1].Text[0][0].Text != "OK" {
+		t.Fatalf("unexpected shapes: %+v", sh)
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestConnector(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	box := func(name string, x, y Dimension) Shape {
+		return Shape{Name: name, X: x, Y: y, W: 40 * MilliMeter, H: 20 * MilliMeter, Lines: SimpleLines(name)}
+	}
+	s := Slide{
+		Shapes: []Shape{box("a", 10*MilliMeter, 10*MilliMeter), box("b", 100*MilliMeter, 60*MilliMeter)},
+		Connectors: []Connector{
+			{From: "a", To: "b", Type: ConnectorElbow, Tail: ArrowTriangle},
+			{From: "b", To: "a", FromSite: SiteTop, ToSite: SiteBottom, Type: ConnectorCurved},
+			{X1: 10 * MilliMeter, Y1: 100 * MilliMeter, X2: 5 * MilliMeter, Y2: 90 * MilliMeter, Head: ArrowOval},
+		},
+	}
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	// An ellipse has 8 connection sites, the left one has index 2.
+	e := box("e", 100*MilliMeter, 10*MilliMeter)
+	e.Geometry = ShapeEllipse
+	if err := f.Add(Slide{Shapes: []Shape{box("a", 10*MilliMeter, 10*MilliMeter), e}, Connectors: []Connector{{From: "a", To: "e"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if x, err := f.xmlDoc("ppt/slides/slide2.xml"); err != nil {
+		t.Fatal(err)
+	} else if x.FindElement("//a:endCxn[@idx='2']") == nil {
+		t.Fatal("wrong connection site of the ellipse")
+	}
+	e.Geometry = ShapeStar5
+	if err := f.Add(Slide{Shapes: []Shape{e}, Connectors: []Connector{{To: "e"}}}); err == nil {
+		t.Fatal("expected an error for an unsupported geometry")
+	}
+	if err := f.Add(Slide{Connectors: []Connector{{From: "missing"}}}); err == nil {
+		t.Fatal("expected an error for a missing shape")
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cxns := x.FindElements("//p:cxnSp")
+	if len(cxns) != 3 {
+		t.Fatalf("expected 3 connectors, got %d", len(cxns))
+	}
+	ids := make(map[string]string)
+	for _, e := range x.FindElements("//p:sp/p:nvSpPr/p:cNvPr") {
+		ids[e.SelectAttrValue("name", "")] = e.SelectAttrValue("id", "")
+	}
+	st, end := cxns[0].FindElement(".//a:stCxn"), cxns[0].FindElement(".//a:endCxn")
+	if st == nil || end == nil || st.SelectAttrValue("id", "") != ids["a"] || end.SelectAttrValue("id", "") != ids["b"] {
+		t.Fatal("connector is not glued")
+	}
+	if st.SelectAttrValue("idx", "") != "3" || end.SelectAttrValue("idx", "") != "1" {
+		t.Fatal("wrong automatic connection sites")
+	}
+	// From the right side of a (50,20) to the left side of b (100,70).
+	off, ext := cxns[0].FindElement(".//a:off"), cxns[0].FindElement(".//a:ext")
+	if off.SelectAttrValue("x", "") != fmt.Sprint(50*MilliMeter) || ext.SelectAttrValue("cy", "") != fmt.Sprint(50*MilliMeter) {
+		t.Fatal("wrong connector position")
+	}
+	if cxns[2].FindElement(".//a:xfrm[@flipH='1'][@flipV='1']") == nil || cxns[2].FindElement(".//a:headEnd[@type='oval']") == nil {
+		t.Fatal("wrong unglued connector")
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestGroup(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	g := Group{
+		Name: "diagram",
+		X:    0, Y: 0, W: 200 * MilliMeter, H: 100 * MilliMeter,
+		TextBoxes: []TextBox{{X: 10 * MilliMeter, Y: 10 * MilliMeter, Lines: SimpleLines("label")}},
+		Images:    []Image{NewImage(m, 20*MilliMeter, 20*MilliMeter, 10*MilliMeter, 10*MilliMeter)},
+		Shapes:    []Shape{{Name: "box", X: 50 * MilliMeter, Y: 10 * MilliMeter, W: 50 * MilliMeter, H: 40 * MilliMeter}},
+		Groups: []Group{{
+			Shapes: []Shape{{X: 0, Y: 60 * MilliMeter, W: 10 * MilliMeter, H: 10 * MilliMeter, Geometry: ShapeEllipse}},
+		}},
+	}
+	s := Slide{
+		Shapes:     []Shape{{Name: "outside", X: 250 * MilliMeter, Y: 10 * MilliMeter, W: 20 * MilliMeter, H: 20 * MilliMeter}},
+		Groups:     []Group{g},
+		Connectors: []Connector{{From: "box", To: "outside"}},
+	}
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	webp := Image{W: Inch, H: Inch, Extension: "webp", Data: []byte{0}}
+	if err := f.Add(Slide{Groups: []Group{{Images: []Image{webp}}}}); err == nil {
+		t.Fatal("expected an error for an unknown image type in a group")
+	}
+	if err := f.Add(Slide{Layout: "twoObj", Placeholders: []Placeholder{{Idx: 2, Image: &webp}}}); err == nil {
+		t.Fatal("expected an error for an unknown image type in a placeholder")
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.FindElement("/p:sld/p:cSld/p:spTree/p:grpSpPr/a:xfrm") == nil {
+		t.Fatal("malformed root group properties")
+	}
+	grps := x.FindElements("//p:grpSp")
+	if len(grps) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(grps))
+	}
+	xfrm := grps[0].FindElement("p:grpSpPr/a:xfrm")
+	if e := xfrm.SelectElement("a:chOff"); e.SelectAttrValue("x", "") != "0" || e.SelectAttrValue("y", "") != fmt.Sprint(10*MilliMeter) {
+		t.Fatal("wrong child offset")
+	}
+	if e := xfrm.SelectElement("a:chExt"); e.SelectAttrValue("cx", "") != fmt.Sprint(100*MilliMeter) || e.SelectAttrValue("cy", "") != fmt.Sprint(60*MilliMeter) {
+		t.Fatal("wrong child extent")
+	}
+	ids := make(map[string]bool)
+	for _, e := range x.FindElements("//p:cNvPr") {
+		id := e.SelectAttrValue("id", "")
+		if ids[id] {
+			t.Fatalf("duplicate shape id %s", id)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 9 {
+		t.Fatalf("expected 9 shape ids, got %d", len(ids))
+	}
+	// The box is at x=100..200mm on the slide after scaling the group by 2.
+	if off := x.FindElement("//p:cxnSp/p:spPr/a:xfrm/a:off"); off.SelectAttrValue("x", "") != fmt.Sprint(200*MilliMeter) {
+		t.Fatalf("connector does not start at the scaled group shape: %s", off.SelectAttrValue("x", ""))
+	}
+	infos, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos[0].Images) != 1 {
+		t.Fatal("group image is missing")
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestShapeIds(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Slide{
+		Layout:       "Title and Content",
+		TextBoxes:    []TextBox{{Lines: SimpleLines("a")}, {Lines: SimpleLines("b")}},
+		ItemBoxes:    []ItemBox{{Items: SimpleItems("x")}},
+		Images:       []Image{NewImage(greyImage(), 0, 0, 10*MilliMeter, 10*MilliMeter)},
+		Placeholders: []Placeholder{{Type: "title", Lines: SimpleLines("title")}},
+		Tables:       []Table{NewTable([][]string{{"1"}}, 0, 0, 10*MilliMeter, 10*MilliMeter)},
+		Shapes:       []Shape{{W: MilliMeter, H: MilliMeter}},
+		Connectors:   []Connector{{X2: MilliMeter}},
+		Groups:       []Group{{Shapes: []Shape{{W: MilliMeter, H: MilliMeter}}}},
+	}
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, names := make(map[string]bool), make(map[string]bool)
+	for _, e := range x.FindElements("//p:cNvPr") {
+		id, name := e.SelectAttrValue("id", ""), e.SelectAttrValue("name", "")
+		if ids[id] || names[name] {
+			t.Fatalf("duplicate shape id %s or name %q", id, name)
+		}
+		ids[id], names[name] = true, true
+	}
+	if len(ids) != 11 {
+		t.Fatalf("expected 11 shapes, got %d", len(ids))
+	}
+	if e := x.FindElement("//p:sp/p:nvSpPr/p:cNvPr"); e.SelectAttrValue("id", "") != "2" || e.SelectAttrValue("name", "") != "TextBox 1" {
+		t.Fatal("the first text box should have id 2")
+	}
+
+	// The allocator continues after the shapes of an existing slide.
+	a := newShapeIds(x.FindElement("//p:spTree"))
+	if id, name := a.new("TextBox", ""); id != 12 || name != "TextBox 11" {
+		t.Fatalf("got id %d, name %q", id, name)
+	}
+	if _, name := a.new("TextBox", "TextBox 1"); name != "TextBox 1" {
+		t.Fatal("explicit names are kept")
+	}
+}
+
+func TestRunFormat(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	line := Line{
+		{Text: "H"},
+		{Text: "2", Baseline: Subscript},
+		{Text: "O ", Italic: true, Underline: UnderlineWavy},
+		{Text: "42", Bold: true, Color: red, Highlight: color.RGBA{255, 255, 0, 255}, Spacing: 1.5},
+		{Text: "old", Strike: true, Font: Font{Name: "Courier New", Size: 12}},
+	}
+	if err := f.Add(Slide{TextBoxes: []TextBox{{Lines: []Line{line}}}}); err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := x.FindElements("//p:txBody/a:p/a:r")
+	if len(runs) != 5 {
+		t.Fatalf("expected 5 runs, got %d", len(runs))
+	}
+	if runs[0].SelectElement("a:rPr") != nil {
+		t.Fatal("plain runs have no properties")
+	}
+	if runs[1].FindElement("a:rPr[@baseline='-25000']") == nil {
+		t.Fatal("missing subscript")
+	}
+	if runs[3].FindElement("a:rPr[@b='1'][@spc='150']/a:highlight/a:srgbClr[@val='FFFF00']") == nil {
+		t.Fatal("missing bold highlight")
+	}
+	if runs[3].FindElement("a:rPr/a:solidFill/a:srgbClr[@val='FF0000']") == nil {
+		t.Fatal("color without font is missing")
+	}
+	if runs[4].FindElement("a:rPr[@strike='sngStrike'][@sz='1200']/a:latin[@typeface='Courier New']") == nil {
+		t.Fatal("missing run font")
+	}
+	infos, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := infos[0].Shapes[0].Text[0]
+	for i := range line {
+		w, g := line[i], got[i]
+		if w.Text != g.Text || w.Bold != g.Bold || w.Italic != g.Italic || w.Underline != g.Underline || w.Strike != g.Strike ||
+			w.Baseline != g.Baseline || w.Spacing != g.Spacing || w.Font != g.Font || (w.Highlight == nil) != (g.Highlight == nil) {
+			t.Fatalf("run %d: expected %+v, got %+v", i, w, g)
+		}
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestParagraph(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb := TextBox{Lines: SimpleLines("centered\nright to left\ndefault")}
+	tb.Lines[0][0].Paragraph = Paragraph{Align: AlignCenter, SpaceBefore: 6, SpaceAfter: 3, LineSpacing: 1.5}
+	tb.Lines[1][0].Paragraph = Paragraph{Align: AlignRight, RTL: true, MarginLeft: 10 * MilliMeter, Indent: -Offset(5 * MilliMeter), Tabs: []Tab{{Pos: 20 * MilliMeter, Align: AlignDecimal}}}
+	ib := ItemBox{Items: SimpleItems("a\n-b")}
+	ib.Items[1].Paragraph.Align = AlignJustify
+	sh := Shape{W: Inch, H: Inch, Lines: []Line{{{Text: "shape", Paragraph: Paragraph{Align: AlignRight}}}}}
+	if err := f.Add(Slide{Layout: "obj", TextBoxes: []TextBox{tb}, ItemBoxes: []ItemBox{ib}, Shapes: []Shape{sh}}); err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := x.FindElements("//p:txBody/a:p")
+	if len(ps) != 6 {
+		t.Fatalf("expected 6 paragraphs, got %d", len(ps))
+	}
+	if ps[0].FindElement("a:pPr[@algn='ctr']/a:lnSpc/a:spcPct[@val='150000']") == nil || ps[0].FindElement("a:pPr/a:spcBef/a:spcPts[@val='600']") == nil {
+		t.Fatal("wrong first paragraph")
+	}
+	if ps[1].FindElement("a:pPr[@algn='r'][@rtl='1'][@indent='-180000']/a:tabLst/a:tab[@algn='dec']") == nil {
+		t.Fatal("wrong second paragraph")
+	}
+	if ps[2].FindElement("a:pPr") != nil {
+		t.Fatal("a line without paragraph format must use the defaults")
+	}
+	if ps[0].ChildElements()[0].Tag != "pPr" {
+		t.Fatal("a:pPr must be the first child")
+	}
+	if ps[3].FindElement("a:pPr[@algn]") != nil || ps[4].FindElement("a:pPr[@lvl='1'][@algn='just']") == nil {
+		t.Fatal("wrong item paragraphs")
+	}
+	if ps[5].FindElement("a:pPr[@algn='r']") == nil {
+		t.Fatal("wrong shape paragraph")
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestLinks(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{TextBoxes: []TextBox{{Lines: SimpleLines("first")}}}); err != nil {
+		t.Fatal(err)
+	}
+	im := NewImage(greyImage(), 0, 0, 10*MilliMeter, 10*MilliMeter)
+	im.Link = "#next"
+	s := Slide{
+		TextBoxes: []TextBox{{Lines: []Line{{
+			{Text: "dashboard", Link: "https://example.com/kpi?a=1&b=2"},
+			{Text: "mail", Link: "mailto:team@example.com"},
+			{Text: "back", Link: "#1"},
+		}}}},
+		Shapes: []Shape{{W: MilliMeter, H: MilliMeter, Link: "https://example.com/shape"}},
+		Images: []Image{im},
+		Notes:  []Line{{{Text: "see", Link: "https://example.com/notes"}}},
+	}
+	if err := f.Add(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{TextBoxes: []TextBox{{Lines: []Line{{{Text: "x", Link: "#9"}}}}}}); err == nil {
+		t.Fatal("expected an error for a link to a missing slide")
+	}
+	rels, err := f.rels("ppt/slides/slide2.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byId := make(map[string]Relationship)
+	for _, r := range rels {
+		byId[r.ID] = r
+	}
+	x, err := f.xmlDoc("ppt/slides/slide2.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targets []string
+	for _, e := range x.FindElements("//a:hlinkClick") {
+		if e.SelectAttr("link") != nil {
+			t.Fatal("unresolved link")
+		}
+		if id := e.SelectAttrValue("r:id", ""); id != "" {
+			r := byId[id]
+			if r.Kind() == "hyperlink" != r.External {
+				t.Fatalf("wrong relationship: %+v", r)
+			}
+			targets = append(targets, r.Target)
+		} else {
+			targets = append(targets, e.SelectAttrValue("action", ""))
+		}
+	}
+	want := []string{"https://example.com/kpi?a=1&b=2", "mailto:team@example.com", "ppt/slides/slide1.xml", "ppaction://hlinkshowjump?jump=nextslide", "https://example.com/shape"}
+	sort.Strings(want)
+	sort.Strings(targets)
+	if fmt.Sprint(targets) != fmt.Sprint(want) {
+		t.Fatalf("expected links %v, got %v", want, targets)
+	}
+	info, err := f.Slides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notes string
+	for _, r := range info[1].Relationships {
+		if r.Kind() == "notesSlide" {
+			notes = r.Target
+		}
+	}
+	nrels, err := f.rels(notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, r := range nrels {
+		found = found || (r.External && r.Target == "https://example.com/notes")
+	}
+	if !found {
+		t.Fatal("missing link in notes")
+	}
+
+	// Importing the second slide alone drops the link to the first slide.
+	dst, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.ImportSlides(&f, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := dst.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files := checkPackage(t, b.Bytes())
+	if bytes.Contains(files["ppt/slides/slide1.xml"], []byte("hlinksldjump")) {
+		t.Fatal("dangling slide link")
+	}
+
+	// A link to a slide which is imported later in the same call is kept.
+	dst, err = New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.ImportSlides(&f, []int{1, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if x, err := dst.xmlDoc("ppt/slides/slide1.xml"); err != nil {
+		t.Fatal(err)
+	} else if x.FindElement("//a:hlinkClick[@action='ppaction://hlinksldjump']") == nil {
+		t.Fatal("missing link to a slide imported later")
+	}
+	rels, err = dst.rels("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = false
+	for _, r := range rels {
+		found = found || r.Kind() == "slide" && r.Target == "ppt/slides/slide2.xml"
+	}
+	if !found {
+		t.Fatalf("missing slide relationship: %v", rels)
+	}
+	b.Reset()
+	if _, err := dst.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+	b.Reset()
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+
+	// Deleting a slide removes the links to it.
+	g, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add(Slide{TextBoxes: []TextBox{{Lines: SimpleLines("first")}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add(Slide{Shapes: []Shape{{W: Inch, H: Inch, Link: "#1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.DeleteSlide(0); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if _, err := g.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files = checkPackage(t, b.Bytes())
+	for _, name := range []string{"ppt/slides/slide1.xml", "ppt/slides/_rels/slide1.xml.rels"} {
+		if _, ok := files[name]; ok {
+			t.Fatalf("deleted part is still present: %s", name)
+		}
+	}
+	if bytes.Contains(files["[Content_Types].xml"], []byte("slide1.xml")) {
+		t.Fatal("content type of deleted slide is still present")
+	}
+	if bytes.Contains(files["ppt/slides/slide2.xml"], []byte("hlinksldjump")) || bytes.Contains(files["ppt/slides/_rels/slide2.xml.rels"], []byte("slide1.xml")) {
+		t.Fatal("link to the deleted slide is still present")
+	}
+}
+
+func TestTextBoxSize(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := SimpleLines(strings.Repeat("word ", 40))
+	boxes := []TextBox{
+		{Lines: SimpleLines("short\nlines")},
+		{Lines: long, Width: 80 * MilliMeter, Wrap: true},
+		{
+			Lines: long, Width: 80 * MilliMeter, Height: 30 * MilliMeter, Wrap: true,
+			Autofit: AutofitShrink, Insets: &Insets{}, Anchor: "b", Columns: 2, ColumnSpacing: 5 * MilliMeter,
+		},
+	}
+	if err := f.Add(Slide{TextBoxes: boxes}); err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sps := x.FindElements("//p:sp")
+	ext := func(i int) (Dimension, Dimension) {
+		return parsePoint(sps[i].FindElement("p:spPr/a:xfrm/a:ext"), "cx", "cy")
+	}
+	// 5 characters of 18pt and 2 lines.
+	if w, h := ext(0); w != Dimension(0.55*18*12700*5)+182880 || h != Dimension(2*1.2*18*12700)+91440 {
+		t.Fatalf("wrong computed size: %d %d", w, h)
+	}
+	if sps[0].FindElement("p:txBody/a:bodyPr[@wrap='none']") == nil {
+		t.Fatal("boxes without wrap have wrap=none")
+	}
+	if w, h := ext(1); w != 80*MilliMeter || h < 4*Dimension(1.2*18*12700) {
+		t.Fatalf("wrapped text needs more lines: %d %d", w, h)
+	}
+	if w, h := ext(2); w != 80*MilliMeter || h != 30*MilliMeter {
+		t.Fatal("explicit size is kept")
+	}
+	bodyPr := sps[2].FindElement("p:txBody/a:bodyPr[@wrap='square'][@lIns='0'][@anchor='b'][@numCol='2'][@spcCol='180000']")
+	if bodyPr == nil || bodyPr.SelectElement("a:normAutofit") == nil {
+		t.Fatal("wrong body properties")
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkPackage(t, b.Bytes())
+}
+
+func TestImageData(t *testing.T) {
+	var jpg, gf bytes.Buffer
+	if err := jpeg.Encode(&jpg, greyImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&gf, greyImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	bmp := append([]byte("BM"), make([]byte, 60)...)
+	tiff := append([]byte("II*\x00"), make([]byte, 8)...)
+	wmf := append([]byte("\xd7\xcd\xc6\x9a"), make([]byte, 18)...)
+	var images []Image
+	for i, data := range [][]byte{jpg.Bytes(), gf.Bytes(), bmp, tiff, wmf} {
+		im, err := NewImageData(data, Dimension(i)*20*MilliMeter, 0, 10*MilliMeter, 10*MilliMeter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"jpeg", "gif", "bmp", "tiff", "wmf"}[i]; im.Extension != want {
+			t.Fatalf("expected %s, got %s", want, im.Extension)
+		}
+		images = append(images, im)
+	}
+	if _, err := NewImageData([]byte("not an image"), 0, 0, 0, 0); err == nil {
+		t.Fatal("expected an error for unknown data")
+	}
+	small, err := NewImage(greyImage(), 0, 0, 0, 0).Jpeg(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if small.Extension != "jpeg" || imageFormat(small.Data) != "jpeg" {
+		t.Fatal("transcode failed")
+	}
+	if _, err := images[2].Jpeg(50); err == nil {
+		t.Fatal("bmp cannot be transcoded")
+	}
+
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{Images: images}); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files := checkPackage(t, b.Bytes())
+	if !bytes.Equal(files["ppt/media/image1.jpeg"], jpg.Bytes()) {
+		t.Fatal("jpeg data is not stored unchanged")
+	}
+	ct := string(files["[Content_Types].xml"])
+	for _, s := range []string{"image/jpeg", "image/gif", "image/bmp", "image/tiff", "image/x-wmf"} {
+		if !strings.Contains(ct, `ContentType="`+s+`"`) {
+			t.Fatalf("missing content type %s", s)
+		}
+	}
+}
+
+func TestSvg(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100" viewBox="0 0 400 200">
+<rect width="400" height="200" fill="white"/>
+<rect x="20" y="20" width="100" height="60" style="fill:#f00;stroke:black;stroke-width:4"/>
+<g transform="translate(0,100)">
+<circle cx="300" cy="60" r="40" fill="blue" fill-opacity="0.5"/>
+<path d="M200 50 a40 40 0 1 0 80 0 a40 40 0 1 0 -80 0" fill="none" stroke="rgb(0,128,0)" stroke-width="6"/>
+<text x="10" y="10">not rendered</text>
+</g>
+</svg>`)
+	m, err := rasterizeSvg(svg, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := m.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("wrong size: %v", b)
+	}
+	for _, c := range []struct {
+		x, y    int
+		r, g, b uint8
+	}{
+		{35, 25, 255, 0, 0},      // inside the red rectangle
+		{10, 10, 0, 0, 0},        // stroke of the rectangle
+		{5, 5, 255, 255, 255},    // background
+		{150, 80, 128, 128, 255}, // half transparent blue circle
+		{100, 75, 0, 128, 0},     // left of the stroked arc circle
+		{120, 75, 255, 255, 255}, // inside the arc circle
+	} {
+		r, g, b, _ := m.At(c.x, c.y).RGBA()
+		if d := math.Abs(float64(r>>8)-float64(c.r)) + math.Abs(float64(g>>8)-float64(c.g)) + math.Abs(float64(b>>8)-float64(c.b)); d > 6 {
+			t.Fatalf("pixel %d,%d: expected %d %d %d, got %d %d %d", c.x, c.y, c.r, c.g, c.b, r>>8, g>>8, b>>8)
+		}
+	}
+	if _, err := rasterizeSvg([]byte("<html/>"), 0, 0); err == nil {
+		t.Fatal("expected an error for a non svg document")
+	}
+	// currentColor is inherited, unknown colors are not painted.
+	m, err = rasterizeSvg([]byte(`<svg width="20" height="20"><rect width="20" height="20" fill="white"/>
+<g color="red"><rect width="10" height="10" fill="currentColor"/></g>
+<rect x="10" width="10" height="10" fill="chartreuse"/>
+<rect y="10" width="10" height="10" fill="#0000ff80"/></svg>`), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		x, y    int
+		r, g, b uint8
+	}{{5, 5, 255, 0, 0}, {15, 5, 255, 255, 255}, {5, 15, 127, 127, 255}} {
+		r, g, b, _ := m.At(c.x, c.y).RGBA()
+		if d := math.Abs(float64(r>>8)-float64(c.r)) + math.Abs(float64(g>>8)-float64(c.g)) + math.Abs(float64(b>>8)-float64(c.b)); d > 6 {
+			t.Fatalf("pixel %d,%d: expected %d %d %d, got %d %d %d", c.x, c.y, c.r, c.g, c.b, r>>8, g>>8, b>>8)
+		}
+	}
+	// Arc flags may be written without separators.
+	if got := fmt.Sprint(pathTokens("a1 1 0 011 1")); got != "[a 1 1 0 0 1 1 1]" {
+		t.Fatalf("wrong arc tokens: %s", got)
+	}
+	compact, _ := svgPath("M0 10a10 10 0 1010 0")
+	spaced, _ := svgPath("M0 10 a10 10 0 1 0 10 0")
+	if fmt.Sprint(compact) != fmt.Sprint(spaced) {
+		t.Fatal("compact arc flags are parsed differently")
+	}
+	// Malformed content is rendered as far as possible.
+	for _, el := range []string{
+		`<path d="M0 0 L10 0 L10 10 Z 5 5"/>`,
+		`<rect y="NaN" width="10" height="10"/>`,
+		`<rect x="Inf" y="-Inf" width="1e308" height="1e308"/>`,
+		`<path d="M0 -1e300 L10 1e300 L5 5 Z"/>`,
+	} {
+		if _, err := rasterizeSvg([]byte(`<svg width="20" height="20">`+el+`</svg>`), 0, 0); err != nil {
+			t.Fatalf("%s: %s", el, err)
+		}
+	}
+
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := NewSvg(svg, nil, 0, 0, 100*MilliMeter, 50*MilliMeter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom, err := NewSvg(svg, greyImage(), 0, 0, 10*MilliMeter, 10*MilliMeter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{Images: []Image{im, custom}}); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files := checkPackage(t, b.Bytes())
+	if !bytes.Equal(files["ppt/media/image1.svg"], svg) {
+		t.Fatal("missing svg part")
+	}
+	fallback, err := png.Decode(bytes.NewReader(files["ppt/media/image1.png"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := fallback.Bounds(); b.Dx() != int(100*MilliMeter*Dpi/Inch) {
+		t.Fatalf("fallback has wrong size: %v", b)
+	}
+	x := etree.NewDocument()
+	if err := x.ReadFromBytes(files["ppt/slides/slide1.xml"]); err != nil {
+		t.Fatal(err)
+	}
+	blips := x.FindElements("//asvg:svgBlip")
+	if len(blips) != 2 {
+		t.Fatalf("expected 2 svg blips, got %d", len(blips))
+	}
+	if !strings.Contains(string(files["ppt/slides/_rels/slide1.xml.rels"]), `Id="`+blips[0].SelectAttrValue("r:embed", "")+`"`) {
+		t.Fatal("svg blip has no relationship")
+	}
+	if !strings.Contains(string(files["[Content_Types].xml"]), "image/svg+xml") {
+		t.Fatal("missing svg content type")
+	}
+}
+
+func TestImageSize(t *testing.T) {
+	encode := func(w, h int) []byte {
+		var b bytes.Buffer
+		png.Encode(&b, image.NewGray(image.Rect(0, 0, w, h)))
+		return b.Bytes()
+	}
+	// Insert a pHYs chunk with 11811 pixels per meter (300 dpi) after IHDR.
+	withPhys := func(p []byte) []byte {
+		chunk := []byte("\x00\x00\x00\x09pHYs\x00\x00\x2e\x23\x00\x00\x2e\x23\x01")
+		chunk = append(chunk, make([]byte, 4)...)
+		binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+		return append(append(append([]byte{}, p[:33]...), chunk...), p[33:]...)
+	}
+	var j bytes.Buffer
+	jpeg.Encode(&j, image.NewGray(image.Rect(0, 0, 72, 36)), nil)
+	jfif := append([]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x01\x00\x48\x00\x48\x00\x00"), j.Bytes()[2:]...)
+
+	near := func(a, b Dimension) bool { return math.Abs(float64(a)-float64(b)) < 1000 }
+	for i, c := range []struct {
+		data       []byte
+		w, h       Dimension
+		fit        string
+		x, y, W, H Dimension
+	}{
+		{data: encode(96, 48), W: Inch, H: Inch / 2},
+		{data: withPhys(encode(600, 300)), W: 2 * Inch, H: Inch},
+		{data: jfif, W: Inch, H: Inch / 2},
+		{data: encode(96, 48), w: 4 * Inch, W: 4 * Inch, H: 2 * Inch},
+		{data: encode(96, 48), w: 2 * Inch, h: 2 * Inch, fit: FitContain, y: Inch / 2, W: 2 * Inch, H: Inch},
+		{data: encode(96, 48), w: 2 * Inch, h: 2 * Inch, fit: FitCover, W: 2 * Inch, H: 2 * Inch},
+	} {
+		im, err := NewImageData(c.data, 0, 0, c.w, c.h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		im.Fit = c.fit
+		if err := im.layout(); err != nil {
+			t.Fatal(err)
+		}
+		if !near(im.X, c.x) || !near(im.Y, c.y) || !near(im.W, c.W) || !near(im.H, c.H) {
+			t.Fatalf("%d: expected %d %d %d %d, got %d %d %d %d", i, c.x, c.y, c.W, c.H, im.X, im.Y, im.W, im.H)
+		}
+		if c.fit == FitCover && im.srcRect() != [4]int{25000, 0, 25000, 0} {
+			t.Fatalf("wrong crop: %v", im.srcRect())
+		}
+	}
+	if err := (&Image{Extension: "emf", Data: []byte{1}}).layout(); err == nil {
+		t.Fatal("expected an error for an emf without size")
+	}
+	if err := (&Image{W: Inch, Extension: "png", Data: encode(96, 48), Fit: "contian"}).layout(); err == nil {
+		t.Fatal("expected an error for an unknown fit")
+	}
+
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := NewImage(greyImage(), 0, 0, 2*Inch, Inch)
+	im.Fit = FitCover
+	if err := f.Add(Slide{Images: []Image{im}}); err != nil {
+		t.Fatal(err)
+	}
+	x, err := f.xmlDoc("ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.FindElement("//p:blipFill/a:srcRect[@t]") == nil {
+		t.Fatal("missing srcRect crop")
+	}
+}
+
+func TestImageTransform(t *testing.T) {
+	var b bytes.Buffer
+	png.Encode(&b, image.NewGray(image.Rect(0, 0, 200, 100)))
+	im, err := NewImageData(b.Bytes(), 0, 0, Inch, Inch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cropping half of the width leaves a square, which covers without further cropping.
+	im.Crop = Crop{Left: 20, Right: 30}
+	im.Fit = FitCover
+	im.Rotation = 90
+	im.FlipH = true
+	im.Geometry = ShapeEllipse
+	if err := im.layout(); err != nil {
+		t.Fatal(err)
+	}
+	if r := im.srcRect(); r != [4]int{20000, 0, 30000, 0} {
+		t.Fatalf("wrong srcRect: %v", r)
+	}
+	im.Crop = Crop{Top: 10}
+	if err := im.layout(); err != nil {
+		t.Fatal(err)
+	}
+	if r := im.srcRect(); r != [4]int{27500, 10000, 27500, 0} {
+		t.Fatalf("wrong srcRect: %v", r)
+	}
+	im.Crop = Crop{Left: 60, Right: 40}
+	if err := im.layout(); err == nil {
+		t.Fatal("expected an error for a completely cropped image")
+	}
+	stretch := Image{W: Inch, H: Inch, Extension: "png", Data: b.Bytes(), Crop: Crop{Left: 60, Right: 60}}
+	if _, err := stretch.build("rId2", newShapeIds(minimalSlide().FindElement("p:sld/p:cSld/p:spTree"))); err == nil {
+		t.Fatal("expected an error for a completely cropped image")
+	}
+	im.Crop = Crop{Left: 20, Right: 30}
+
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Slide{Images: []Image{im}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := checkPackage(t, buf.Bytes())
+	x := etree.NewDocument()
+	if err := x.ReadFromBytes(files["ppt/slides/slide1.xml"]); err != nil {
+		t.Fatal(err)
+	}
+	src := x.FindElement("//p:blipFill/a:srcRect")
+	if src.SelectAttrValue("l", "") != "20000" || src.SelectAttrValue("r", "") != "30000" || src.SelectAttr("t") != nil {
+		t.Fatalf("wrong srcRect: %v", src.Attr)
+	}
+	xfrm := x.FindElement("//p:pic/p:spPr/a:xfrm")
+	if xfrm.SelectAttrValue("rot", "") != "5400000" || xfrm.SelectAttrValue("flipH", "") != "1" || xfrm.SelectAttr("flipV") != nil {
+		t.Fatalf("wrong xfrm: %v", xfrm.Attr)
+	}
+	if x.FindElement("//p:pic/p:spPr/a:prstGeom[@prst='ellipse']") == nil {
+		t.Fatal("missing ellipse geometry")
+	}
+}
+
+func TestMediaDedupe(t *testing.T) {
+	f, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logo := NewImage(greyImage(), 0, 0, Inch, Inch)
+	for i := 0; i < 3; i++ {
+		s := Slide{Images: []Image{logo, logo}, Groups: []Group{{Images: []Image{logo}}}}
+		if err := f.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var b bytes.Buffer
+	if _, err := f.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files := checkPackage(t, b.Bytes())
+	media := func(files map[string][]byte) (v []string) {
+		for name := range files {
+			if strings.HasPrefix(name, "ppt/media/") {
+				v = append(v, name)
+			}
+		}
+		sort.Strings(v)
+		return v
+	}
+	if m := media(files); len(m) != 1 || m[0] != "ppt/media/image1.png" {
+		t.Fatalf("expected a single media part, got %v", m)
+	}
+	if n := strings.Count(string(files["ppt/slides/_rels/slide3.xml.rels"]), `Target="../media/image1.png"`); n != 3 {
+		t.Fatalf("expected 3 relationships to the media part, got %d", n)
+	}
+
+	// Media of the input package is reused, new content gets a new part.
+	g, err := OpenReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewImage(image.NewGray(image.Rect(0, 0, 2, 2)), 0, 0, Inch, Inch)
+	if err := g.Add(Slide{Images: []Image{logo, other}}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := g.DeleteSlide(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Reset()
+	if _, err := g.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	files = checkPackage(t, b.Bytes())
+	if m := fmt.Sprint(media(files)); m != "[ppt/media/image1.png ppt/media/image2.png]" {
+		t.Fatalf("wrong media parts: %s", m)
+	}
+}
@@ -0,0 +1,243 @@
+package pptx
+
+import (
+	"image/color"
+	"path"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// SlideInfo describes an existing slide of the presentation.
+type SlideInfo struct {
+	Name          string         // Part name, e.g. "ppt/slides/slide1.xml".
+	Layout        string         // Part name of the slide layout, e.g. "ppt/slideLayouts/slideLayout1.xml".
+	Shapes        []ShapeInfo    // Shapes in document order, the content of groups follows the group.
+	TextBoxes     []TextBox      // Text of all shapes with text, including placeholders.
+	Images        []Image        // Pictures with their media data.
+	Relationships []Relationship // Relationships of the slide, e.g. to the layout and to images.
+	Notes         []Line         // Text of the speaker notes.
+}
+
+// ShapeInfo describes a shape on an existing slide.
+// Placeholders without a position inherit it from the layout and have zero X, Y, W, H.
+// Children of a group follow the group and are reported in the child coordinates of the group (a:chOff, a:chExt).
+type ShapeInfo struct {
+	ID             int       // Shape id (cNvPr id).
+	Name           string    // Shape name (cNvPr name).
+	Kind           string    // Element name: "sp", "pic", "graphicFrame", "grpSp" or "cxnSp".
+	Placeholder    string    // Placeholder type, e.g. "title", "body" or "obj"; empty if it is no placeholder.
+	PlaceholderIdx int       // Placeholder index.
+	X, Y, W, H     Dimension // Position and size.
+	Text           []Line    // Text content, if any.
+	Media          string    // Part name of the picture, e.g. "ppt/media/image1.png".
+	font           Font      // Font of the first text run.
+	embed          string    // Relationship id of the picture.
+	geometry       string    // Preset geometry, e.g. "rect".
+}
+
+// Slides parses all slides of the presentation in the order of the slide list.
+func (f *File) Slides() ([]SlideInfo, error) {
+	parts, err := f.slideParts()
+	if err != nil {
+		return nil, err
+	}
+	slides := make([]SlideInfo, len(parts))
+	for i, name := range parts {
+		if slides[i], err = f.slideInfo(name); err != nil {
+			return nil, err
+		}
+	}
+	return slides, nil
+}
+
+// slideInfo parses a single slide.
+func (f *File) slideInfo(name string) (SlideInfo, error) {
+	si := SlideInfo{Name: name}
+	x, err := f.xmlDoc(name)
+	if err != nil {
+		return si, err
+	}
+	if si.Relationships, err = f.rels(name); err != nil {
+		return si, err
+	}
+	targets := make(map[string]string)
+	for _, r := range si.Relationships {
+		targets[r.ID] = r.Target
+		if r.Kind() == "slideLayout" {
+			si.Layout = r.Target
+		}
+	}
+	if si.Notes, err = f.slideNotes(si.Relationships); err != nil {
+		return si, err
+	}
+	spTree := x.FindElement("/p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return si, nil
+	}
+	si.Shapes = parseShapes(spTree, nil)
+	for i, s := range si.Shapes {
+		if s.Kind == "pic" {
+			media, ok := targets[s.embed]
+			if !ok {
+				continue
+			}
+			si.Shapes[i].Media = media
+			data, err := f.readFile(media)
+			if err != nil {
+				return si, err
+			}
+			ext := path.Ext(media)
+			if ext != "" {
+				ext = ext[1:]
+			}
+			si.Images = append(si.Images, Image{X: s.X, Y: s.Y, W: s.W, H: s.H, Extension: ext, Data: data})
+		} else if s.Text != nil {
+			tb := TextBox{
+				X:     s.X,
+				Y:     s.Y,
+				Lines: s.Text,
+				Title: s.Placeholder == "title" || s.Placeholder == "ctrTitle",
+				Font:  s.font,
+			}
+			si.TextBoxes = append(si.TextBoxes, tb)
+		}
+	}
+	return si, nil
+}
+
+// parseShapes appends the shapes of a shape tree or group to v.
+func parseShapes(tree *etree.Element, v []ShapeInfo) []ShapeInfo {
+	for _, e := range tree.ChildElements() {
+		if e.Space != "p" {
+			continue
+		}
+		var nv, xfrm *etree.Element
+		switch e.Tag {
+		case "sp":
+			nv, xfrm = e.SelectElement("p:nvSpPr"), e.FindElement("p:spPr/a:xfrm")
+		case "pic":
+			nv, xfrm = e.SelectElement("p:nvPicPr"), e.FindElement("p:spPr/a:xfrm")
+		case "cxnSp":
+			nv, xfrm = e.SelectElement("p:nvCxnSpPr"), e.FindElement("p:spPr/a:xfrm")
+		case "graphicFrame":
+			nv, xfrm = e.SelectElement("p:nvGraphicFramePr"), e.SelectElement("p:xfrm")
+		case "grpSp":
+			nv, xfrm = e.SelectElement("p:nvGrpSpPr"), e.FindElement("p:grpSpPr/a:xfrm")
+		default:
+			continue
+		}
+		s := ShapeInfo{Kind: e.Tag}
+		if nv != nil {
+			if c := nv.SelectElement("p:cNvPr"); c != nil {
+				s.ID, _ = strconv.Atoi(c.SelectAttrValue("id", ""))
+				s.Name = c.SelectAttrValue("name", "")
+			}
+			if ph := nv.FindElement("p:nvPr/p:ph"); ph != nil {
+				s.Placeholder = ph.SelectAttrValue("type", "obj")
+				s.PlaceholderIdx, _ = strconv.Atoi(ph.SelectAttrValue("idx", "0"))
+			}
+		}
+		if xfrm != nil {
+			s.X, s.Y = parsePoint(xfrm.SelectElement("a:off"), "x", "y")
+			s.W, s.H = parsePoint(xfrm.SelectElement("a:ext"), "cx", "cy")
+		}
+		if txBody := e.SelectElement("p:txBody"); txBody != nil {
+			s.Text = parseText(txBody)
+			if rPr := txBody.FindElement("a:p/a:r/a:rPr"); rPr != nil {
+				s.font.Size, _ = strconv.ParseFloat(rPr.SelectAttrValue("sz", "0"), 64)
+				s.font.Size /= 100
+				if latin := rPr.SelectElement("a:latin"); latin != nil {
+					s.font.Name = latin.SelectAttrValue("typeface", "")
+				}
+			}
+		}
+		if g := e.FindElement("./*/a:prstGeom"); g != nil {
+			s.geometry = g.SelectAttrValue("prst", "")
+		}
+		if blip := e.FindElement("p:blipFill/a:blip"); blip != nil {
+			s.embed = blip.SelectAttrValue("r:embed", "")
+		}
+		v = append(v, s)
+		if e.Tag == "grpSp" {
+			v = parseShapes(e, v)
+		}
+	}
+	return v
+}
+
+// parsePoint returns two dimension attributes of an element.
+func parsePoint(e *etree.Element, a, b string) (Dimension, Dimension) {
+	if e == nil {
+		return 0, 0
+	}
+	return parseDimension(e.SelectAttrValue(a, "")), parseDimension(e.SelectAttrValue(b, ""))
+}
+
+// parseDimension converts an EMU attribute value. Negative or invalid values are returned as 0.
+func parseDimension(s string) Dimension {
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return Dimension(n)
+	}
+	return 0
+}
+
+// parseText returns the paragraphs of a text body as lines.
+func parseText(txBody *etree.Element) []Line {
+	var lines []Line
+	for _, p := range txBody.SelectElements("a:p") {
+		line := Line{}
+		for _, r := range p.ChildElements() {
+			if r.Tag != "r" && r.Tag != "fld" {
+				continue
+			}
+			le := LineElement{}
+			if t := r.SelectElement("a:t"); t != nil {
+				le.Text = t.Text()
+			}
+			if rPr := r.SelectElement("a:rPr"); rPr != nil {
+				parseRun(rPr, &le)
+			}
+			line = append(line, le)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseRun sets the formatting of a line element from the run properties.
+func parseRun(rPr *etree.Element, le *LineElement) {
+	if c := rPr.FindElement("a:solidFill/a:srgbClr"); c != nil {
+		le.Color = parseColor(c.SelectAttrValue("val", ""))
+	}
+	if c := rPr.FindElement("a:highlight/a:srgbClr"); c != nil {
+		le.Highlight = parseColor(c.SelectAttrValue("val", ""))
+	}
+	le.Bold = rPr.SelectAttrValue("b", "0") == "1"
+	le.Italic = rPr.SelectAttrValue("i", "0") == "1"
+	if u := rPr.SelectAttrValue("u", "none"); u != "none" {
+		le.Underline = u
+	}
+	le.Strike = rPr.SelectAttrValue("strike", "noStrike") != "noStrike"
+	b, _ := strconv.Atoi(rPr.SelectAttrValue("baseline", "0"))
+	le.Baseline = b / 1000
+	spc, _ := strconv.Atoi(rPr.SelectAttrValue("spc", "0"))
+	le.Spacing = float64(spc) / 100
+	sz, _ := strconv.Atoi(rPr.SelectAttrValue("sz", "0"))
+	le.Font.Size = float64(sz) / 100
+	if latin := rPr.SelectElement("a:latin"); latin != nil {
+		le.Font.Name = latin.SelectAttrValue("typeface", "")
+	}
+}
+
+// parseColor converts "RRGGBB" to a color. It returns nil for invalid input.
+func parseColor(s string) color.Color {
+	if len(s) != 6 {
+		return nil
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil
+	}
+	return color.RGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 0xFF}
+}
@@ -0,0 +1,122 @@
+package pptx
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// ReplaceText replaces all occurrences of the keys in r by their values
+// in the text of all slides, slide layouts and notes.
+// A key may be split into several text runs with different formatting, as PowerPoint often does.
+// The replacement gets the formatting of the run with the start of the key.
+func (f *File) ReplaceText(r map[string]string) error {
+	keys := make([]string, 0, len(r))
+	for k := range r {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	// Prefer longer keys, if they start at the same position.
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, name := range f.names() {
+		if !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		if !strings.HasPrefix(name, "ppt/slides/slide") && !strings.HasPrefix(name, "ppt/slideLayouts/slideLayout") && !strings.HasPrefix(name, "ppt/notesSlides/notesSlide") {
+			continue
+		}
+		x, err := f.xmlDoc(name)
+		if err != nil {
+			return err
+		}
+		for _, p := range x.FindElements("//a:p") {
+			// Text is replaced within each sequence of consecutive runs.
+			// Line breaks and fields split a paragraph.
+			var runs []*etree.Element
+			for _, e := range p.ChildElements() {
+				if e.Space == "a" && e.Tag == "r" {
+					runs = append(runs, e)
+				} else if e.Space == "a" && e.Tag != "pPr" {
+					replaceRuns(runs, keys, r)
+					runs = nil
+				}
+			}
+			replaceRuns(runs, keys, r)
+		}
+	}
+	return nil
+}
+
+// replaceRuns replaces text in a sequence of text runs.
+func replaceRuns(runs []*etree.Element, keys []string, r map[string]string) {
+	if len(runs) == 0 {
+		return
+	}
+	texts := make([]string, len(runs))
+	for i, e := range runs {
+		if t := e.SelectElement("a:t"); t != nil {
+			texts[i] = t.Text()
+		}
+	}
+	removed := make([]bool, len(runs))
+	changed := false
+	for pos := 0; ; {
+		joined := strings.Join(texts, "")
+		start, key := -1, ""
+		for _, k := range keys {
+			if i := strings.Index(joined[pos:], k); i >= 0 && (start < 0 || pos+i < start) {
+				start, key = pos+i, k
+			}
+		}
+		if start < 0 {
+			break
+		}
+		end := start + len(key)
+
+		// Find the runs which contain the first and the last byte of the key.
+		i, j, offi, offj := -1, -1, 0, 0
+		for k, off := 0, 0; k < len(texts); k++ {
+			if i < 0 && start < off+len(texts[k]) {
+				i, offi = k, off
+			}
+			if end <= off+len(texts[k]) {
+				j, offj = k, off
+				break
+			}
+			off += len(texts[k])
+		}
+		// The key takes the formatting of the first run, text after it stays in the last run.
+		rest := texts[j][end-offj:]
+		texts[i] = texts[i][:start-offi] + r[key]
+		for k := i + 1; k <= j; k++ {
+			texts[k] = ""
+			removed[k] = true
+		}
+		if j == i {
+			texts[i] += rest
+		} else {
+			texts[j] = rest
+		}
+		pos = start + len(r[key])
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	for i, e := range runs {
+		if removed[i] && texts[i] == "" {
+			e.Parent().RemoveChild(e)
+		} else if t := e.SelectElement("a:t"); t != nil {
+			t.SetText(texts[i])
+		} else {
+			e.CreateElement("a:t").SetText(texts[i])
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package pptx
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// Preset geometries of a Shape.
+// Any other preset name of DrawingML (ST_ShapeType) can be used as well.
+const (
+	ShapeRect       = "rect"
+	ShapeRoundRect  = "roundRect"
+	ShapeEllipse    = "ellipse"
+	ShapeTriangle   = "triangle"
+	ShapeDiamond    = "diamond"
+	ShapeHexagon    = "hexagon"
+	ShapeRightArrow = "rightArrow"
+	ShapeLeftArrow  = "leftArrow"
+	ShapeChevron    = "chevron"
+	ShapeHomePlate  = "homePlate"
+	ShapeStar5      = "star5"
+)
+
+// Dash styles of an Outline.
+const (
+	DashSolid    = "solid"
+	DashDot      = "sysDot"
+	DashDash     = "dash"
+	DashLongDash = "lgDash"
+	DashDashDot  = "dashDot"
+)
+
+// A Shape is an autoshape with a preset geometry and optional text.
+type Shape struct {
+	X, Y, W, H Dimension
+	Name       string      // Shape name, used to glue connectors. Default is "Shape N".
+	Geometry   string      // Preset geometry, e.g. ShapeRoundRect. Default is ShapeRect.
+	Fill       color.Color // Solid fill color, nil for no fill.
+	Outline    Outline     // Border of the shape.
+	Lines      []Line      // Text inside the shape.
+	Font       Font        // Can be unspecified for defaults.
+	Anchor     string      // Vertical text anchor: "t", "ctr" or "b". Default is "ctr".
+	Link       string      // Click action: a url, or a slide, e.g. "#3" or "#next".
+}
+
+// Outline is the line style of a shape.
+// A zero Outline draws no line.
+type Outline struct {
+	Color color.Color // Line color.
+	Width Dimension   // Line width, e.g. 12700 for 1pt.
+	Dash  string      // Dash style, e.g. DashDot. Default is solid.
+}
+
+// addShape adds a shape to the slide's xml tree.
+func (s *Slide) addShape(sh Shape) error {
+	spTree := s.xml.FindElement("p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return fmt.Errorf("Cannot find spTree")
+	}
+	spTree.AddChild(sh.build(s.ids.new("Shape", sh.Name)))
+	return nil
+}
+
+// build returns the p:sp element of the shape.
+func (sh Shape) build(id int, name string) *etree.Element {
+	geom := sh.Geometry
+	if geom == "" {
+		geom = ShapeRect
+	}
+	sp := etree.NewElement("p:sp")
+	nv := sp.CreateElement("p:nvSpPr")
+	cNvPr := nv.CreateElement("p:cNvPr")
+	cNvPr.CreateAttr("id", strconv.Itoa(id))
+	cNvPr.CreateAttr("name", name)
+	if sh.Link != "" {
+		hlinkClick(cNvPr, sh.Link)
+	}
+	nv.CreateElement("p:cNvSpPr")
+	nv.CreateElement("p:nvPr")
+
+	spPr := sp.CreateElement("p:spPr")
+	xfrm := spPr.CreateElement("a:xfrm")
+	setPoint(xfrm.CreateElement("a:off"), "x", "y", sh.X, sh.Y)
+	setPoint(xfrm.CreateElement("a:ext"), "cx", "cy", sh.W, sh.H)
+	prstGeom := spPr.CreateElement("a:prstGeom")
+	prstGeom.CreateAttr("prst", geom)
+	prstGeom.CreateElement("a:avLst")
+	if sh.Fill != nil {
+		spPr.CreateElement("a:solidFill").CreateElement("a:srgbClr").CreateAttr("val", rgb(sh.Fill))
+	} else {
+		spPr.CreateElement("a:noFill")
+	}
+	spPr.AddChild(sh.Outline.build())
+
+	if len(sh.Lines) > 0 {
+		anchor := sh.Anchor
+		if anchor == "" {
+			anchor = "ctr"
+		}
+		txBody := sp.CreateElement("p:txBody")
+		bodyPr := txBody.CreateElement("a:bodyPr")
+		bodyPr.CreateAttr("rtlCol", "0")
+		bodyPr.CreateAttr("anchor", anchor)
+		txBody.CreateElement("a:lstStyle")
+		tb := TextBox{Font: sh.Font}
+		for _, line := range sh.Lines {
+			txBody.AddChild(tb.buildLine(line).Root())
+		}
+	}
+	return sp
+}
+
+// build returns the a:ln element of the outline.
+func (o Outline) build() *etree.Element {
+	ln := etree.NewElement("a:ln")
+	if o.Color == nil {
+		ln.CreateElement("a:noFill")
+		return ln
+	}
+	if o.Width > 0 {
+		ln.CreateAttr("w", strconv.FormatUint(uint64(o.Width), 10))
+	}
+	ln.CreateElement("a:solidFill").CreateElement("a:srgbClr").CreateAttr("val", rgb(o.Color))
+	if o.Dash != "" {
+		ln.CreateElement("a:prstDash").CreateAttr("val", o.Dash)
+	}
+	return ln
+}
@@ -3,6 +3,7 @@ package pptx
 import (
 	"fmt"
 	"io"
+	"path"
 	"strconv"
 	"strings"
 
@@ -10,29 +11,81 @@ import (
 )
 
 // Slide holds the content of a slide which can be added to the presentation.
-// It supports TextBoxes and Images.
+// It supports TextBoxes, ItemBoxes, Images, Placeholders of the slide layout,
+// Tables, Charts, Shapes, Connectors and Groups.
 type Slide struct {
-	TextBoxes []TextBox       // TextBoxes.
-	ItemBoxes []ItemBox       // ItemBoxes.
-	Images    []Image         // Images will be encoded as png.
-	Master    int             // Slide layout master id. Default is 1
-	n         int             // Slide number
-	name      string          // slide file name, e.g.: slide5.xml, if n is 5.
-	rId       string          // relationship id of the slide, e.g. "rId9"
-	id        string          // slice id in ppt/presentation.xml slide list, e.g. "256"
-	xml       *etree.Document // slide xml tree.
+	TextBoxes    []TextBox       // TextBoxes.
+	ItemBoxes    []ItemBox       // ItemBoxes.
+	Images       []Image         // Images, e.g. from NewImage or NewImageData.
+	Placeholders []Placeholder   // Content of layout placeholders.
+	Tables       []Table         // Native tables.
+	Charts       []Chart         // Native charts with embedded data.
+	Shapes       []Shape         // Autoshapes with preset geometry.
+	Connectors   []Connector     // Lines between points or shapes.
+	Groups       []Group         // Groups of text boxes, images and shapes.
+	Notes        []Line          // Speaker notes.
+	Master       int             // Slide layout master id. Default is 1
+	Layout       string          // Slide layout name or type, e.g. "Title and Content" or "obj". It overrides Master.
+	layout       string          // Part name of the slide layout.
+	n            int             // Slide number
+	name         string          // slide file name, e.g.: slide5.xml, if n is 5.
+	rId          string          // relationship id of the slide, e.g. "rId9"
+	id           string          // slice id in ppt/presentation.xml slide list, e.g. "256"
+	xml          *etree.Document // slide xml tree.
+	rels         []Relationship  // Relationships of the slide, except for the layout.
+	parts        []slidePart     // New parts used by the slide, e.g. charts.
+	ids          *shapeIds       // Allocator for shape ids.
+}
+
+// slidePart is a new part of the package, which is created with a slide.
+type slidePart struct {
+	name        string
+	data        io.WriterTo
+	contentType string
+	override    bool // Content type is registered for the part, not as a default for the extension.
+}
+
+// addRel adds a relationship to the slide and returns it's id.
+// Internal targets are part names. The slide layout is always rId1.
+func (s *Slide) addRel(typ, target string, external bool) string {
+	id := fmt.Sprintf("rId%d", len(s.rels)+2)
+	s.rels = append(s.rels, Relationship{ID: id, Type: typ, Target: target, External: external})
+	return id
+}
+
+// addPart adds a new part to the slide. The name is changed to be unique, e.g.
+// ppt/charts/chart1.xml becomes ppt/charts/chart3.xml, if there are already 2 charts.
+func (s *Slide) addPart(f *File, name string, data io.WriterTo, contentType string, override bool) string {
+	used := func(name string) bool {
+		for _, p := range s.parts {
+			if p.name == name {
+				return true
+			}
+		}
+		return f.exists(name)
+	}
+	ext := path.Ext(name)
+	base := strings.TrimRight(strings.TrimSuffix(name, ext), "0123456789")
+	for i := 1; used(name); i++ {
+		name = fmt.Sprintf("%s%d%s", base, i, ext)
+	}
+	s.parts = append(s.parts, slidePart{name, data, contentType, override})
+	return name
 }
 
 // Add appends a slide to the presentation.
 func (f *File) Add(s Slide) error {
-	if f.numSlides == 0 {
-		// Only count the first time the file is read.
-		f.numSlides = f.slideCount()
+	if err := f.selectLayout(&s); err != nil {
+		return err
 	}
-	f.numSlides++
-	s.n = f.numSlides
+	s.n = f.nextSlideNumber()
 	s.name = fmt.Sprintf("slide%d.xml", s.n)
 
+	// The slide is built first, the presentation is only changed if it succeeds.
+	if err := s.build(f); err != nil {
+		return err
+	}
+
 	if err := f.addToContentTypes(s); err != nil {
 		return err
 	}
@@ -41,13 +94,14 @@ func (f *File) Add(s Slide) error {
 		return err
 	}
 
-	/*
-		if err := f.addPngImage(s); err != nil {
+	for _, p := range s.parts {
+		f.m[p.name] = p.data
+		if p.contentType == "" {
+			continue // relationship files and media with a default content type
+		}
+		if err := f.addContentType(p.name, p.contentType, p.override); err != nil {
 			return err
 		}
-	*/
-	if err := s.build(f); err != nil {
-		return err
 	}
 
 	if err := f.addSlideFile(s); err != nil {
@@ -62,34 +116,88 @@ func (f *File) Add(s Slide) error {
 		return err
 	}
 
+	if len(s.Notes) > 0 {
+		if err := f.addNotes("ppt/slides/"+s.name, s.Notes); err != nil {
+			return err
+		}
+	}
+
 	// deb.Println("TODO: (f pptx.File) Add(s slide) is not finished.")
 
 	return nil
 }
 
+// nextSlideNumber returns the number of the next new slide file.
+func (f *File) nextSlideNumber() int {
+	if f.numSlides == 0 {
+		// Only count the first time the file is read.
+		f.numSlides = f.slideCount()
+	}
+	// Slide numbers may have gaps, if slides have been deleted.
+	f.numSlides++
+	for f.exists(fmt.Sprintf("ppt/slides/slide%d.xml", f.numSlides)) {
+		f.numSlides++
+	}
+	return f.numSlides
+}
+
 // build builds the slide xml tree.
 func (s *Slide) build(f *File) error {
 	s.xml = minimalSlide()
-	for i, tb := range s.TextBoxes {
-		if err := s.addTextBox(tb, i); err != nil {
+	s.ids = newShapeIds(s.xml.FindElement("p:sld/p:cSld/p:spTree"))
+	for _, tb := range s.TextBoxes {
+		if err := s.addTextBox(tb); err != nil {
 			return err
 		}
 	}
-	for i, ib := range s.ItemBoxes {
-		if err := s.addItemBox(ib, i); err != nil {
+	for _, ib := range s.ItemBoxes {
+		if err := s.addItemBox(ib); err != nil {
 			return err
 		}
 	}
-	for i, im := range s.Images {
-		if err := s.addImageRef(im, i); err != nil {
+	for _, im := range s.Images {
+		if err := s.addImageRef(f, im); err != nil {
 			return err
-		} else {
-			if err = f.addImageFile(im, i, s.n); err != nil {
-				return err
-			}
 		}
 	}
-	return nil
+	for _, ph := range s.Placeholders {
+		if err := s.addPlaceholder(f, ph); err != nil {
+			return err
+		}
+	}
+	for _, t := range s.Tables {
+		if err := s.addTable(t); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.Charts {
+		if err := s.addChart(f, c); err != nil {
+			return err
+		}
+	}
+	for _, sh := range s.Shapes {
+		if err := s.addShape(sh); err != nil {
+			return err
+		}
+	}
+	for _, g := range s.Groups {
+		if err := s.addGroup(f, g); err != nil {
+			return err
+		}
+	}
+	// Connectors are added last, they may be glued to any other shape.
+	for _, c := range s.Connectors {
+		if err := s.addConnector(c); err != nil {
+			return err
+		}
+	}
+	slides, err := f.slideParts()
+	if err != nil {
+		return err
+	}
+	return resolveLinks(s.xml.Root(), slides, func(typ, target string, external bool) (string, error) {
+		return s.addRel(typ, target, external), nil
+	})
 }
 
 // addToContentTypes adds the new slide reference to [Conent_Types].xml.
@@ -110,6 +218,11 @@ func (f *File) addToContentTypes(slide Slide) error {
 		if e := needsType(x, "emf"); e != nil {
 			return e
 		}
+		for _, im := range slide.Images {
+			if e := needsType(x, im.Extension); e != nil {
+				return e
+			}
+		}
 		/*
 			if hasType(x) == false {
 				e := addPngType(x)
@@ -140,12 +253,7 @@ func needsType(d *etree.Document, ext string) error {
 			}
 		}
 	}
-	types := map[string]string{
-		"png": "image/png",
-		"emf": "image/x-emf",
-		//"wmf": "image/x-wmf",
-	}
-	mim, o := types[ext]
+	mim, o := imageTypes[ext]
 	if !o {
 		return fmt.Errorf("mime type unknown for: %s", ext)
 	}
@@ -267,19 +375,21 @@ func (f *File) addSlideRels(slide Slide) error {
 	if rootElement == nil {
 		return fmt.Errorf("%s: Cannot find <Relationships...", relFile)
 	}
-	if slide.Master == 0 {
-		slide.Master = 1
-	}
 	e := rootElement.CreateElement("Relationship")
 	e.CreateAttr("Id", "rId1") // Is this always the id of layout 1?
 	e.CreateAttr("Type", "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout")
-	e.CreateAttr("Target", fmt.Sprintf("../slideLayouts/slideLayout%d.xml", slide.Master))
-	// Add relations for each image in the slide.
-	for i, m := range slide.Images {
+	e.CreateAttr("Target", relativeTarget("ppt/slides/"+slide.name, slide.layout))
+	// Add relations for images and other content of the slide: rId2...
+	for _, r := range slide.rels {
 		e := rootElement.CreateElement("Relationship")
-		e.CreateAttr("Id", fmt.Sprintf("rId%d", i+2)) // rId2...
-		e.CreateAttr("Type", "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image")
-		e.CreateAttr("Target", fmt.Sprintf("../media/slide%dimage%d.%s", slide.n, i, m.Extension))
+		e.CreateAttr("Id", r.ID)
+		e.CreateAttr("Type", r.Type)
+		if r.External {
+			e.CreateAttr("Target", r.Target)
+			e.CreateAttr("TargetMode", "External")
+		} else {
+			e.CreateAttr("Target", relativeTarget("ppt/slides/"+slide.name, r.Target))
+		}
 	}
 	// Add the file to the map.
 	f.m[relFile] = &d
@@ -359,6 +469,9 @@ func (f *File) readXml(filePath string) error {
 	if _, ok := f.m[filePath]; ok {
 		return nil // File is already read.
 	}
+	if f.d[filePath] {
+		return fmt.Errorf("%s: file has been deleted.", filePath)
+	}
 	for _, v := range f.r.File {
 		if v.Name == filePath {
 			if r, err := v.Open(); err != nil {
@@ -398,7 +511,6 @@ func minimalSlide() *etree.Document {
 		<p:nvPr/>
 		</p:nvGrpSpPr>
 		<p:grpSpPr>
-		<p:grpSpPr>
 		<a:xfrm>
 		<a:off x="0" y="0"/>
 		<a:ext cx="0" cy="0"/>
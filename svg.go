@@ -0,0 +1,771 @@
+package pptx
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// NewSvg returns an image from SVG data.
+//
+// The SVG is shown by PowerPoint 2016 and later, older viewers show a PNG fallback.
+// If fallback is nil, it is rendered by a built-in rasterizer, which supports a subset of SVG:
+// paths, basic shapes, groups with transforms, solid fill and stroke colors and opacity.
+// Text, gradients, patterns, clipping and embedded images are not rendered.
+func NewSvg(svg []byte, fallback image.Image, x, y, w, h Dimension) (Image, error) {
+	if fallback == nil {
+		m, err := rasterizeSvg(svg, int(w*Dpi/Inch), int(h*Dpi/Inch))
+		if err != nil {
+			return Image{}, err
+		}
+		fallback = m
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, fallback); err != nil {
+		return Image{}, err
+	}
+	return Image{X: x, Y: y, W: w, H: h, Extension: "png", Data: b.Bytes(), Svg: svg}, nil
+}
+
+// addSvg adds the svg part of an image to the slide and references it from the blip of the picture.
+func (s *Slide) addSvg(f *File, pic *etree.Element, im Image) error {
+	name, err := s.addMedia(f, "svg", im.Svg)
+	if err != nil {
+		return err
+	}
+	extLst := pic.FindElement("p:blipFill/a:blip/a:extLst")
+	if extLst == nil {
+		extLst = pic.FindElement("p:blipFill/a:blip").CreateElement("a:extLst")
+	}
+	ext := extLst.CreateElement("a:ext")
+	ext.CreateAttr("uri", "{96DAC541-7B7A-43D3-8B79-37D633B846F1}")
+	svgBlip := ext.CreateElement("asvg:svgBlip")
+	svgBlip.CreateAttr("xmlns:asvg", "http://schemas.microsoft.com/office/drawing/2016/SVG/main")
+	svgBlip.CreateAttr("r:embed", s.addRel(relTypeImage, name, false))
+	return nil
+}
+
+// point is a point in pixel coordinates.
+type point struct{ x, y float64 }
+
+// matrix is an affine transformation [a b c d e f]: x' = a*x + c*y + e, y' = b*x + d*y + f.
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+func (m matrix) mul(n matrix) matrix { // m applied after n
+	return matrix{
+		m[0]*n[0] + m[2]*n[1], m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3], m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4], m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+func (m matrix) apply(p point) point {
+	return point{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// svgStyle holds the inherited presentation attributes.
+type svgStyle struct {
+	fill, stroke         color.Color
+	color                color.Color // value of currentColor
+	width                float64
+	opacity, fillOpacity float64
+	strokeOpacity        float64
+	evenOdd              bool
+	transform            matrix
+}
+
+// rasterizeSvg renders svg data to an image with the given size in pixels.
+// A zero size uses the size of the svg.
+func rasterizeSvg(data []byte, width, height int) (image.Image, error) {
+	d := etree.NewDocument()
+	if err := d.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("svg: %s", err)
+	}
+	root := d.Root()
+	if root == nil || root.Tag != "svg" {
+		return nil, fmt.Errorf("svg: missing svg element")
+	}
+	vb := svgNumbers(root.SelectAttrValue("viewBox", ""))
+	w, h := svgLength(root.SelectAttrValue("width", "")), svgLength(root.SelectAttrValue("height", ""))
+	if len(vb) != 4 || vb[2] <= 0 || vb[3] <= 0 {
+		if w == 0 || h == 0 {
+			w, h = 300, 150
+		}
+		vb = []float64{0, 0, w, h}
+	} else if w == 0 || h == 0 {
+		w, h = vb[2], vb[3]
+	}
+	switch {
+	case width == 0 && height == 0:
+		width, height = int(math.Ceil(w)), int(math.Ceil(h))
+	case width == 0:
+		width = int(math.Ceil(float64(height) * w / h))
+	case height == 0:
+		height = int(math.Ceil(float64(width) * h / w))
+	}
+	if width <= 0 || height <= 0 || width*height > 1<<26 {
+		return nil, fmt.Errorf("svg: invalid size %dx%d", width, height)
+	}
+	// The view box is scaled uniformly and centered (preserveAspectRatio xMidYMid meet).
+	scale := math.Min(float64(width)/vb[2], float64(height)/vb[3])
+	dx := (float64(width) - scale*vb[2]) / 2
+	dy := (float64(height) - scale*vb[3]) / 2
+	r := &rasterizer{
+		m: image.NewRGBA(image.Rect(0, 0, width, height)),
+		c: make([]float64, width*height),
+	}
+	st := svgStyle{
+		fill: color.Black, color: color.Black, width: 1, opacity: 1, fillOpacity: 1, strokeOpacity: 1,
+		transform: matrix{scale, 0, 0, scale, dx - scale*vb[0], dy - scale*vb[1]},
+	}
+	r.group(root, st)
+	return r.m, nil
+}
+
+// rasterizer draws filled polygons with anti-aliasing.
+type rasterizer struct {
+	m *image.RGBA
+	c []float64 // coverage buffer, it is cleared after each fill
+}
+
+// group draws the children of an svg container element.
+func (r *rasterizer) group(e *etree.Element, st svgStyle) {
+	for _, c := range e.ChildElements() {
+		r.element(c, st)
+	}
+}
+
+// element draws an svg element.
+func (r *rasterizer) element(e *etree.Element, st svgStyle) {
+	st = st.apply(e)
+	if st.opacity == 0 || e.SelectAttrValue("display", "") == "none" || e.SelectAttrValue("visibility", "") == "hidden" {
+		return
+	}
+	n := func(a string) float64 { return svgLength(e.SelectAttrValue(a, "0")) }
+	var paths [][]point
+	var closed []bool
+	switch e.Tag {
+	case "g", "a", "svg":
+		r.group(e, st)
+		return
+	case "rect":
+		x, y, w, h := n("x"), n("y"), n("width"), n("height")
+		paths, closed = [][]point{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}}, []bool{true}
+	case "circle":
+		paths, closed = [][]point{ellipse(n("cx"), n("cy"), n("r"), n("r"))}, []bool{true}
+	case "ellipse":
+		paths, closed = [][]point{ellipse(n("cx"), n("cy"), n("rx"), n("ry"))}, []bool{true}
+	case "line":
+		paths, closed = [][]point{{{n("x1"), n("y1")}, {n("x2"), n("y2")}}}, []bool{false}
+		st.fill = nil
+	case "polyline", "polygon":
+		v := svgNumbers(e.SelectAttrValue("points", ""))
+		var p []point
+		for i := 0; i+1 < len(v); i += 2 {
+			p = append(p, point{v[i], v[i+1]})
+		}
+		paths, closed = [][]point{p}, []bool{e.Tag == "polygon"}
+	case "path":
+		paths, closed = svgPath(e.SelectAttrValue("d", ""))
+	default:
+		return // text, defs, clipPath, image, ... are not rendered
+	}
+	for i := range paths {
+		for j := range paths[i] {
+			paths[i][j] = st.transform.apply(paths[i][j])
+		}
+	}
+	r.draw(paths, closed, st)
+}
+
+// draw fills and strokes transformed paths.
+func (r *rasterizer) draw(paths [][]point, closed []bool, st svgStyle) {
+	if st.fill != nil {
+		r.fill(paths, st.evenOdd, st.fill, st.opacity*st.fillOpacity)
+	}
+	if st.stroke != nil && st.width > 0 {
+		// The stroke width is scaled with the mean scale of the transformation.
+		m := st.transform
+		w := st.width * math.Sqrt(math.Abs(m[0]*m[3]-m[1]*m[2]))
+		r.fill(stroke(paths, closed, w), false, st.stroke, st.opacity*st.strokeOpacity)
+	}
+}
+
+// fill draws polygons with a color using a scanline algorithm with 4x4 supersampling.
+func (r *rasterizer) fill(paths [][]point, evenOdd bool, c color.Color, alpha float64) {
+	type edge struct {
+		x0, y0, x1, y1 float64
+		dir            int
+	}
+	var edges []edge
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, p := range paths {
+		for i := range p {
+			a, b := p[i], p[(i+1)%len(p)]
+			if a.y == b.y {
+				continue
+			}
+			e := edge{a.x, a.y, b.x, b.y, 1}
+			if a.y > b.y {
+				e = edge{b.x, b.y, a.x, a.y, -1}
+			}
+			edges = append(edges, e)
+			ymin, ymax = math.Min(ymin, e.y0), math.Max(ymax, e.y1)
+		}
+	}
+	const ss = 4
+	width, height := r.m.Rect.Dx(), r.m.Rect.Dy()
+	ymin, ymax = math.Max(ymin, 0), math.Min(ymax, float64(height))
+	if !(ymin < ymax) { // no edges, outside of the image or NaN
+		return
+	}
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	var xs []crossing
+	j0, j1 := int(math.Floor(ymin*ss)), int(math.Ceil(ymax*ss))
+	for j := j0; j < j1; j++ {
+		y := (float64(j) + 0.5) / ss
+		xs = xs[:0]
+		for _, e := range edges {
+			if y >= e.y0 && y < e.y1 {
+				xs = append(xs, crossing{e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0), e.dir})
+			}
+		}
+		sort.Slice(xs, func(a, b int) bool { return xs[a].x < xs[b].x })
+		row := r.c[(j/ss)*width : (j/ss+1)*width]
+		wind := 0
+		for k := 0; k+1 < len(xs); k++ {
+			wind += xs[k].dir
+			inside := wind != 0
+			if evenOdd {
+				inside = (k+1)%2 == 1
+			}
+			if inside {
+				span(row, xs[k].x, xs[k+1].x, 1.0/ss)
+			}
+		}
+	}
+	cr, cg, cb, ca := c.RGBA()
+	a := alpha * float64(ca) / 0xffff
+	for y := j0 / ss; y < height && y <= j1/ss; y++ {
+		for x := 0; x < width; x++ {
+			cov := r.c[y*width+x]
+			if cov <= 0 {
+				continue
+			}
+			r.c[y*width+x] = 0
+			if cov > 1 {
+				cov = 1
+			}
+			f := cov * a
+			i := r.m.PixOffset(x, y)
+			p := r.m.Pix[i : i+4]
+			// Source over, the color is not premultiplied by ca.
+			src := [3]float64{float64(cr), float64(cg), float64(cb)}
+			if ca > 0 {
+				for k := range src {
+					src[k] = src[k] * 0xffff / float64(ca) / 257
+				}
+			}
+			for k := 0; k < 3; k++ {
+				p[k] = uint8(src[k]*f + float64(p[k])*(1-f) + 0.5)
+			}
+			p[3] = uint8(255*f + float64(p[3])*(1-f) + 0.5)
+		}
+	}
+}
+
+// span adds the horizontal coverage of [x0, x1) times w to a row of pixels.
+func span(row []float64, x0, x1, w float64) {
+	x0, x1 = math.Max(x0, 0), math.Min(x1, float64(len(row)))
+	for x0 < x1 {
+		i := math.Floor(x0)
+		end := math.Min(i+1, x1)
+		row[int(i)] += (end - x0) * w
+		x0 = end
+	}
+}
+
+// stroke returns polygons which cover the stroke of the paths with round joins.
+// All polygons have the same orientation, so that they are united by the nonzero rule.
+func stroke(paths [][]point, closed []bool, w float64) [][]point {
+	var v [][]point
+	add := func(p []point) {
+		area := 0.0
+		for i := range p {
+			a, b := p[i], p[(i+1)%len(p)]
+			area += a.x*b.y - b.x*a.y
+		}
+		if area < 0 {
+			for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+				p[i], p[j] = p[j], p[i]
+			}
+		}
+		v = append(v, p)
+	}
+	h := w / 2
+	for k, p := range paths {
+		n := len(p) - 1
+		if closed[k] {
+			n = len(p)
+		}
+		for i := 0; i < n; i++ {
+			a, b := p[i], p[(i+1)%len(p)]
+			dx, dy := b.x-a.x, b.y-a.y
+			l := math.Hypot(dx, dy)
+			if l == 0 {
+				continue
+			}
+			nx, ny := -dy/l*h, dx/l*h
+			add([]point{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}})
+		}
+		for i, q := range p {
+			if closed[k] || (i > 0 && i < len(p)-1) {
+				if h > 0.75 {
+					add(ellipse(q.x, q.y, h, h))
+				}
+			}
+		}
+	}
+	return v
+}
+
+// ellipse returns a polygon approximating an ellipse.
+func ellipse(cx, cy, rx, ry float64) []point {
+	const n = 64
+	p := make([]point, n)
+	for i := range p {
+		a := 2 * math.Pi * float64(i) / n
+		p[i] = point{cx + rx*math.Cos(a), cy + ry*math.Sin(a)}
+	}
+	return p
+}
+
+// apply returns the style of an element inheriting from st.
+// Presentation attributes are overridden by the style attribute.
+func (st svgStyle) apply(e *etree.Element) svgStyle {
+	attrs := make(map[string]string)
+	for _, a := range e.Attr {
+		attrs[a.Key] = a.Value
+	}
+	for _, decl := range strings.Split(e.SelectAttrValue("style", ""), ";") {
+		if i := strings.Index(decl, ":"); i > 0 {
+			attrs[strings.TrimSpace(decl[:i])] = strings.TrimSpace(decl[i+1:])
+		}
+	}
+	if v, ok := attrs["color"]; ok {
+		if c := svgColor(v, st.color); c != nil {
+			st.color = c
+		}
+	}
+	if v, ok := attrs["fill"]; ok {
+		st.fill = svgColor(v, st.color)
+	}
+	if v, ok := attrs["stroke"]; ok {
+		st.stroke = svgColor(v, st.color)
+	}
+	if v, ok := attrs["stroke-width"]; ok {
+		st.width = svgLength(v)
+	}
+	if v, ok := attrs["fill-rule"]; ok {
+		st.evenOdd = v == "evenodd"
+	}
+	for k, p := range map[string]*float64{"opacity": &st.opacity, "fill-opacity": &st.fillOpacity, "stroke-opacity": &st.strokeOpacity} {
+		if v, ok := attrs[k]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				*p *= math.Max(0, math.Min(1, f))
+			}
+		}
+	}
+	if v, ok := attrs["transform"]; ok {
+		st.transform = st.transform.mul(svgTransform(v))
+	}
+	return st
+}
+
+// svgTransform parses a transform list, e.g. "translate(10,20) scale(2)".
+func svgTransform(s string) matrix {
+	m := identity
+	for {
+		i := strings.Index(s, "(")
+		j := strings.Index(s, ")")
+		if i < 0 || j < i {
+			return m
+		}
+		name := strings.TrimSpace(strings.Trim(s[:i], " ,\t\n"))
+		v := svgNumbers(s[i+1 : j])
+		s = s[j+1:]
+		arg := func(k int, def float64) float64 {
+			if k < len(v) {
+				return v[k]
+			}
+			return def
+		}
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(v) != 6 {
+				continue
+			}
+			copy(t[:], v)
+		case "translate":
+			t = matrix{1, 0, 0, 1, arg(0, 0), arg(1, 0)}
+		case "scale":
+			t = matrix{arg(0, 1), 0, 0, arg(1, arg(0, 1)), 0, 0}
+		case "rotate":
+			a := arg(0, 0) * math.Pi / 180
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = matrix{1, 0, 0, 1, cx, cy}.mul(matrix{math.Cos(a), math.Sin(a), -math.Sin(a), math.Cos(a), 0, 0}).mul(matrix{1, 0, 0, 1, -cx, -cy})
+		case "skewX":
+			t = matrix{1, 0, math.Tan(arg(0, 0) * math.Pi / 180), 1, 0, 0}
+		case "skewY":
+			t = matrix{1, math.Tan(arg(0, 0) * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+}
+
+// svgColor parses a paint value, currentColor is replaced by current.
+// It returns nil for none, paint servers and unknown colors, which are not painted.
+func svgColor(s string, current color.Color) color.Color {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "currentcolor":
+		return current
+	case strings.HasPrefix(s, "#"):
+		h := s[1:]
+		if len(h) == 3 || len(h) == 4 {
+			var b []byte
+			for i := range h {
+				b = append(b, h[i], h[i])
+			}
+			h = string(b)
+		}
+		alpha := uint64(255)
+		if len(h) == 8 {
+			a, err := strconv.ParseUint(h[6:], 16, 8)
+			if err != nil {
+				return nil
+			}
+			h, alpha = h[:6], a
+		}
+		if c := parseColor(strings.ToUpper(h)); c != nil {
+			r, g, b, _ := c.RGBA()
+			return color.NRGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(alpha)}
+		}
+		return nil
+	case strings.HasPrefix(s, "rgb"):
+		if i, j := strings.Index(s, "("), strings.Index(s, ")"); i > 0 && j > i {
+			var c [4]uint8
+			c[3] = 255
+			for k, v := range strings.Split(s[i+1:j], ",") {
+				if k > 3 {
+					break
+				}
+				v = strings.TrimSpace(v)
+				scale := 1.0
+				if k == 3 {
+					scale = 255
+				}
+				if strings.HasSuffix(v, "%") {
+					v, scale = strings.TrimSuffix(v, "%"), 2.55
+				}
+				f, _ := strconv.ParseFloat(v, 64)
+				c[k] = uint8(math.Max(0, math.Min(255, f*scale)))
+			}
+			return color.NRGBA{c[0], c[1], c[2], c[3]}
+		}
+	}
+	return svgColors[s]
+}
+
+// svgColors are common color keywords.
+var svgColors = map[string]color.Color{
+	"black": color.RGBA{0, 0, 0, 255}, "white": color.RGBA{255, 255, 255, 255},
+	"red": color.RGBA{255, 0, 0, 255}, "green": color.RGBA{0, 128, 0, 255}, "blue": color.RGBA{0, 0, 255, 255},
+	"yellow": color.RGBA{255, 255, 0, 255}, "cyan": color.RGBA{0, 255, 255, 255}, "magenta": color.RGBA{255, 0, 255, 255},
+	"gray": color.RGBA{128, 128, 128, 255}, "grey": color.RGBA{128, 128, 128, 255},
+	"lightgray": color.RGBA{211, 211, 211, 255}, "darkgray": color.RGBA{169, 169, 169, 255},
+	"orange": color.RGBA{255, 165, 0, 255}, "purple": color.RGBA{128, 0, 128, 255},
+	"brown": color.RGBA{165, 42, 42, 255}, "navy": color.RGBA{0, 0, 128, 255},
+	"lime": color.RGBA{0, 255, 0, 255}, "maroon": color.RGBA{128, 0, 0, 255},
+	"olive": color.RGBA{128, 128, 0, 255}, "teal": color.RGBA{0, 128, 128, 255},
+	"silver": color.RGBA{192, 192, 192, 255}, "transparent": color.RGBA{},
+}
+
+// svgLength parses a length and converts it to pixels. Percentages are not supported, invalid and non-finite values are 0.
+func svgLength(s string) float64 {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		px     float64
+	}{{"px", 1}, {"pt", 96.0 / 72}, {"pc", 16}, {"in", 96}, {"cm", 96 / 2.54}, {"mm", 96 / 25.4}, {"%", 0}}
+	scale := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, scale = strings.TrimSuffix(s, u.suffix), u.px
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f * scale
+}
+
+// svgNumbers parses a list of numbers separated by whitespace or commas. Non-finite numbers are skipped.
+func svgNumbers(s string) []float64 {
+	var v []float64
+	t := pathTokens(s)
+	for _, tok := range t {
+		if f, err := strconv.ParseFloat(tok, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			v = append(v, f)
+		}
+	}
+	return v
+}
+
+// pathTokens splits path data into commands and numbers, e.g. "M1.5.5-2" into "M", "1.5", ".5", "-2".
+// The flags of an arc are single characters, e.g. "a1 1 0 011 1" has the flags "0" and "1".
+func pathTokens(s string) []string {
+	var v []string
+	var cmd byte // last command
+	n := 0       // numbers since the last command
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0:
+			v = append(v, s[i:i+1])
+			cmd, n = c, 0
+			i++
+		case (cmd == 'A' || cmd == 'a') && (n%7 == 3 || n%7 == 4) && (c == '0' || c == '1'):
+			v = append(v, s[i:i+1])
+			n++
+			i++
+		default:
+			j, dot, exp := i, false, false
+			if s[j] == '-' || s[j] == '+' {
+				j++
+			}
+			for j < len(s) {
+				d := s[j]
+				if d >= '0' && d <= '9' {
+					j++
+				} else if d == '.' && !dot && !exp {
+					dot = true
+					j++
+				} else if (d == 'e' || d == 'E') && !exp && j+1 < len(s) {
+					exp = true
+					j++
+					if s[j] == '-' || s[j] == '+' {
+						j++
+					}
+				} else {
+					break
+				}
+			}
+			if j == i {
+				j++ // skip an unknown character
+			} else {
+				v = append(v, s[i:j])
+				n++
+			}
+			i = j
+		}
+	}
+	return v
+}
+
+// svgPath parses path data and returns the flattened sub paths and if they are closed.
+func svgPath(d string) ([][]point, []bool) {
+	var paths [][]point
+	var closed []bool
+	var cur []point
+	var p, start, ctrl point // current point, start of sub path, last control point
+	var last byte
+	flush := func(close bool) {
+		if len(cur) > 1 {
+			paths = append(paths, cur)
+			closed = append(closed, close)
+		}
+		cur = nil
+	}
+	lineTo := func(q point) {
+		if len(cur) == 0 {
+			cur = append(cur, p)
+		}
+		cur = append(cur, q)
+		p = q
+	}
+	cubic := func(c1, c2, q point) {
+		a := p
+		for i := 1; i <= 16; i++ {
+			t := float64(i) / 16
+			u := 1 - t
+			lineTo(point{
+				u*u*u*a.x + 3*u*u*t*c1.x + 3*u*t*t*c2.x + t*t*t*q.x,
+				u*u*u*a.y + 3*u*u*t*c1.y + 3*u*t*t*c2.y + t*t*t*q.y,
+			})
+		}
+	}
+	quad := func(c, q point) {
+		a := p
+		for i := 1; i <= 12; i++ {
+			t := float64(i) / 12
+			u := 1 - t
+			lineTo(point{u*u*a.x + 2*u*t*c.x + t*t*q.x, u*u*a.y + 2*u*t*c.y + t*t*q.y})
+		}
+	}
+	tokens := pathTokens(d)
+	var cmd byte
+	for i := 0; i < len(tokens); {
+		if t := tokens[i]; len(t) == 1 && strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", t[0]) >= 0 {
+			cmd = t[0]
+			i++
+		} else if cmd == 'Z' || cmd == 'z' {
+			break // a number after closepath is an error, the path is rendered up to it
+		} else if cmd == 'M' {
+			cmd = 'L' // implicit lineto after moveto
+		} else if cmd == 'm' {
+			cmd = 'l'
+		}
+		if cmd == 'Z' || cmd == 'z' {
+			flush(true)
+			p = start
+			last = cmd
+			continue
+		}
+		n := map[byte]int{'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7}[cmd&^0x20]
+		if n == 0 || i+n > len(tokens) {
+			break
+		}
+		a := make([]float64, n)
+		for k := range a {
+			f, err := strconv.ParseFloat(tokens[i+k], 64)
+			if err != nil {
+				return paths, closed
+			}
+			a[k] = f
+		}
+		i += n
+		rel := cmd >= 'a'
+		pt := func(x, y float64) point {
+			if rel {
+				return point{p.x + x, p.y + y}
+			}
+			return point{x, y}
+		}
+		switch cmd &^ 0x20 {
+		case 'M':
+			flush(false)
+			p = pt(a[0], a[1])
+			start = p
+		case 'L':
+			lineTo(pt(a[0], a[1]))
+		case 'H':
+			if rel {
+				lineTo(point{p.x + a[0], p.y})
+			} else {
+				lineTo(point{a[0], p.y})
+			}
+		case 'V':
+			if rel {
+				lineTo(point{p.x, p.y + a[0]})
+			} else {
+				lineTo(point{p.x, a[0]})
+			}
+		case 'C':
+			c1, c2, q := pt(a[0], a[1]), pt(a[2], a[3]), pt(a[4], a[5])
+			cubic(c1, c2, q)
+			ctrl = c2
+		case 'S':
+			c1 := p
+			if l := last &^ 0x20; l == 'C' || l == 'S' {
+				c1 = point{2*p.x - ctrl.x, 2*p.y - ctrl.y}
+			}
+			c2, q := pt(a[0], a[1]), pt(a[2], a[3])
+			cubic(c1, c2, q)
+			ctrl = c2
+		case 'Q':
+			c, q := pt(a[0], a[1]), pt(a[2], a[3])
+			quad(c, q)
+			ctrl = c
+		case 'T':
+			c := p
+			if l := last &^ 0x20; l == 'Q' || l == 'T' {
+				c = point{2*p.x - ctrl.x, 2*p.y - ctrl.y}
+			}
+			quad(c, pt(a[0], a[1]))
+			ctrl = c
+		case 'A':
+			for _, q := range arc(p, a[0], a[1], a[2], a[3] != 0, a[4] != 0, pt(a[5], a[6])) {
+				lineTo(q)
+			}
+		}
+		last = cmd
+	}
+	flush(false)
+	return paths, closed
+}
+
+// arc returns points on an elliptical arc from p to q, excluding p.
+// It converts the endpoint parameterization to the center parameterization (SVG 1.1 F.6.5).
+func arc(p point, rx, ry, rot float64, large, sweep bool, q point) []point {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || p == q {
+		return []point{q}
+	}
+	phi := rot * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (p.x-q.x)/2, (p.y-q.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	f := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		f = -f
+	}
+	cx1, cy1 := f*rx*y1/ry, -f*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (p.x+q.x)/2
+	cy := sin*cx1 + cos*cy1 + (p.y+q.y)/2
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	t1 := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	dt := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && dt > 0 {
+		dt -= 2 * math.Pi
+	} else if sweep && dt < 0 {
+		dt += 2 * math.Pi
+	}
+	n := int(math.Ceil(math.Abs(dt) / (math.Pi / 16)))
+	v := make([]point, n)
+	for i := 1; i <= n; i++ {
+		t := t1 + dt*float64(i)/float64(n)
+		x, y := rx*math.Cos(t), ry*math.Sin(t)
+		v[i-1] = point{cos*x - sin*y + cx, sin*x + cos*y + cy}
+	}
+	v[n-1] = q
+	return v
+}
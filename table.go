@@ -0,0 +1,227 @@
+package pptx
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// Built-in table style ids.
+const (
+	TableStyleMedium2        = "{073A0DAA-6AF3-43AB-8588-CEC1D06C72B9}" // Medium Style 2
+	TableStyleMedium2Accent1 = "{5C22544A-7EE6-4342-B048-85BDC9FD1C3A}" // Medium Style 2 - Accent 1, the default style.
+	TableStyleNoGrid         = "{2D5ABB26-0587-4C30-8999-92F81FD0307C}" // No Style, No Grid
+	TableStyleGrid           = "{5940675A-B579-460E-94D1-54222C63F5DA}" // No Style, Table Grid
+)
+
+// DefaultRowHeight is used for table rows without height.
+const DefaultRowHeight Dimension = 370840
+
+// A Table is a native PowerPoint table.
+type Table struct {
+	X, Y     Dimension
+	Columns  []Dimension // Column widths.
+	Rows     []Dimension // Row heights, missing values are DefaultRowHeight.
+	Cells    [][]Cell    // Rows of cells, each row has a cell for each column.
+	Style    string      // Table style id, e.g. TableStyleMedium2Accent1. Empty uses the presentation's default.
+	FirstRow bool        // Special formatting of the first row.
+	BandRow  bool        // Alternating row formatting.
+}
+
+// A Cell is a table cell.
+// A cell spanning over several columns or rows hides the cells it covers.
+type Cell struct {
+	Lines    []Line      // Text content.
+	Font     Font        // Can be unspecified for defaults.
+	Fill     color.Color // Background color, nil uses the table style.
+	Left     Border
+	Right    Border
+	Top      Border
+	Bottom   Border
+	GridSpan int // Number of columns of a merged cell.
+	RowSpan  int // Number of rows of a merged cell.
+}
+
+// A Border is a cell border line. The zero value uses the table style.
+type Border struct {
+	Width Dimension
+	Color color.Color
+}
+
+// NewTable creates a table from strings.
+// The columns and rows share the width and height equally.
+func NewTable(data [][]string, x, y, w, h Dimension) Table {
+	cols := 0
+	for _, row := range data {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	t := Table{X: x, Y: y, Style: TableStyleMedium2Accent1, FirstRow: true, BandRow: true}
+	for i := 0; i < cols; i++ {
+		t.Columns = append(t.Columns, w/Dimension(cols))
+	}
+	for _, row := range data {
+		t.Rows = append(t.Rows, h/Dimension(len(data)))
+		cells := make([]Cell, cols)
+		for i, s := range row {
+			cells[i].Lines = SimpleLines(s)
+		}
+		t.Cells = append(t.Cells, cells)
+	}
+	return t
+}
+
+// addTable adds a table to the slide's xml tree.
+func (s *Slide) addTable(t Table) error {
+	root, err := t.build(s.ids.new("Table", ""))
+	if err != nil {
+		return err
+	}
+	spTree := s.xml.FindElement("p:sld/p:cSld/p:spTree")
+	if spTree == nil {
+		return fmt.Errorf("Cannot find spTree")
+	}
+	spTree.AddChild(root)
+	return nil
+}
+
+// build creates the graphic frame of the table.
+func (t Table) build(id int, name string) (*etree.Element, error) {
+	cols := len(t.Columns)
+	if cols == 0 {
+		return nil, fmt.Errorf("table has no columns")
+	}
+	var w, h Dimension
+	for _, c := range t.Columns {
+		w += c
+	}
+	rows := make([]Dimension, len(t.Cells))
+	for i := range rows {
+		rows[i] = DefaultRowHeight
+		if i < len(t.Rows) && t.Rows[i] > 0 {
+			rows[i] = t.Rows[i]
+		}
+		h += rows[i]
+	}
+
+	// Mark cells which are covered by merged cells.
+	hMerge, vMerge := make(map[[2]int]bool), make(map[[2]int]bool)
+	for i, row := range t.Cells {
+		if len(row) > cols {
+			return nil, fmt.Errorf("table row %d has %d cells for %d columns", i, len(row), cols)
+		}
+		for j, c := range row {
+			for di := 0; di < c.RowSpan || di == 0; di++ {
+				for dj := 0; dj < c.GridSpan || dj == 0; dj++ {
+					if i+di >= len(t.Cells) || j+dj >= cols {
+						return nil, fmt.Errorf("merged table cell %d/%d exceeds the table", i, j)
+					}
+					if dj > 0 {
+						hMerge[[2]int{i + di, j + dj}] = true
+					}
+					if di > 0 {
+						vMerge[[2]int{i + di, j + dj}] = true
+					}
+				}
+			}
+		}
+	}
+
+	frame := etree.NewElement("p:graphicFrame")
+	nv := frame.CreateElement("p:nvGraphicFramePr")
+	c := nv.CreateElement("p:cNvPr")
+	c.CreateAttr("id", strconv.Itoa(id))
+	c.CreateAttr("name", name)
+	nv.CreateElement("p:cNvGraphicFramePr").CreateElement("a:graphicFrameLocks").CreateAttr("noGrp", "1")
+	nv.CreateElement("p:nvPr")
+	xfrm := frame.CreateElement("p:xfrm")
+	setPoint(xfrm.CreateElement("a:off"), "x", "y", t.X, t.Y)
+	setPoint(xfrm.CreateElement("a:ext"), "cx", "cy", w, h)
+	data := frame.CreateElement("a:graphic").CreateElement("a:graphicData")
+	data.CreateAttr("uri", "http://schemas.openxmlformats.org/drawingml/2006/table")
+	tbl := data.CreateElement("a:tbl")
+	tblPr := tbl.CreateElement("a:tblPr")
+	if t.FirstRow {
+		tblPr.CreateAttr("firstRow", "1")
+	}
+	if t.BandRow {
+		tblPr.CreateAttr("bandRow", "1")
+	}
+	if t.Style != "" {
+		tblPr.CreateElement("a:tableStyleId").SetText(t.Style)
+	}
+	grid := tbl.CreateElement("a:tblGrid")
+	for _, c := range t.Columns {
+		grid.CreateElement("a:gridCol").CreateAttr("w", strconv.FormatUint(uint64(c), 10))
+	}
+	for i, row := range t.Cells {
+		tr := tbl.CreateElement("a:tr")
+		tr.CreateAttr("h", strconv.FormatUint(uint64(rows[i]), 10))
+		for j := 0; j < cols; j++ {
+			var c Cell
+			if j < len(row) {
+				c = row[j]
+			}
+			tr.AddChild(c.build(hMerge[[2]int{i, j}], vMerge[[2]int{i, j}]))
+		}
+	}
+	return frame, nil
+}
+
+// build creates the xml element of a table cell.
+func (c Cell) build(hMerge, vMerge bool) *etree.Element {
+	tc := etree.NewElement("a:tc")
+	if c.GridSpan > 1 {
+		tc.CreateAttr("gridSpan", strconv.Itoa(c.GridSpan))
+	}
+	if c.RowSpan > 1 {
+		tc.CreateAttr("rowSpan", strconv.Itoa(c.RowSpan))
+	}
+	if hMerge {
+		tc.CreateAttr("hMerge", "1")
+	}
+	if vMerge {
+		tc.CreateAttr("vMerge", "1")
+	}
+	txBody := tc.CreateElement("a:txBody")
+	txBody.CreateElement("a:bodyPr")
+	txBody.CreateElement("a:lstStyle")
+	tb := TextBox{Font: c.Font}
+	for _, line := range c.Lines {
+		txBody.AddChild(tb.buildLine(line).Root())
+	}
+	if len(c.Lines) == 0 {
+		txBody.CreateElement("a:p")
+	}
+	tcPr := tc.CreateElement("a:tcPr")
+	for i, b := range []Border{c.Left, c.Right, c.Top, c.Bottom} {
+		if b.Width == 0 && b.Color == nil {
+			continue
+		}
+		ln := tcPr.CreateElement([]string{"a:lnL", "a:lnR", "a:lnT", "a:lnB"}[i])
+		if b.Width > 0 {
+			ln.CreateAttr("w", strconv.FormatUint(uint64(b.Width), 10))
+		}
+		if b.Color != nil {
+			ln.CreateElement("a:solidFill").CreateElement("a:srgbClr").CreateAttr("val", rgb(b.Color))
+		}
+	}
+	if c.Fill != nil {
+		tcPr.CreateElement("a:solidFill").CreateElement("a:srgbClr").CreateAttr("val", rgb(c.Fill))
+	}
+	return tc
+}
+
+// rgb converts the color to "RRGGBB", the alpha value is ignored.
+func rgb(c color.Color) string {
+	return LineElement{Color: c}.color()
+}
+
+// setPoint sets two dimension attributes of an element.
+func setPoint(e *etree.Element, a, b string, x, y Dimension) {
+	e.CreateAttr(a, strconv.FormatUint(uint64(x), 10))
+	e.CreateAttr(b, strconv.FormatUint(uint64(y), 10))
+}
@@ -3,6 +3,7 @@ package pptx
 import (
 	"fmt"
 	"image/color"
+	"math"
 	"strconv"
 	"strings"
 
@@ -11,23 +12,71 @@ import (
 
 // A TextBox can be added to a slide.
 type TextBox struct {
-	X, Y  Dimension // Position
-	Lines []Line    // Lines of (colored) text.
-	Title bool      // Mark this textbox as slide title.
-	Font  Font      // Can be unspecified for defaults.
-	xml   *etree.Document
+	X, Y          Dimension // Position
+	Width, Height Dimension // Size. If zero, it is estimated from the text.
+	Lines         []Line    // Lines of (colored) text.
+	Title         bool      // Mark this textbox as slide title.
+	Font          Font      // Can be unspecified for defaults.
+	Wrap          bool      // Wrap text at the width of the box.
+	Autofit       string    // AutofitNone (default), AutofitShrink or AutofitResize.
+	Insets        *Insets   // Distance of the text to the border. Nil uses the defaults.
+	Anchor        string    // Vertical text anchor: "t" (default), "ctr" or "b".
+	Columns       int       // Number of text columns.
+	ColumnSpacing Dimension // Space between text columns.
+	xml           *etree.Document
 }
 
+// Autofit modes of a TextBox.
+const (
+	AutofitNone   = ""            // The text may overflow the box.
+	AutofitShrink = "normAutofit" // The text is shrunk to fit the box.
+	AutofitResize = "spAutoFit"   // The box is resized to fit the text.
+)
+
+// Insets are the distances of the text to the border of a text box.
+type Insets struct {
+	Left, Top, Right, Bottom Dimension
+}
+
+// defaultInsets are the insets used by PowerPoint.
+var defaultInsets = Insets{Left: 91440, Top: 45720, Right: 91440, Bottom: 45720}
+
 // A Line contains one or more elements (e.g. words) with individual colors.
 type Line []LineElement
 
-// A LineElement is a piece of text with a color.
+// A LineElement is a piece of text with a color and formatting.
 // If Color is nil, the color element is unset.
 type LineElement struct {
-	Text  string      // Text string
-	Color color.Color // Text color, alpha value is ignored.
+	Text      string      // Text string
+	Color     color.Color // Text color, alpha value is ignored.
+	Bold      bool        // Bold text.
+	Italic    bool        // Italic text.
+	Underline string      // Underline style, e.g. UnderlineSingle. Empty for none.
+	Strike    bool        // Strikethrough.
+	Baseline  int         // Vertical offset in percent of the font size, e.g. Superscript or Subscript.
+	Spacing   float64     // Additional character spacing in points, may be negative.
+	Highlight color.Color // Background color of the text.
+	Font      Font        // Font name and size, overrides the font of the text box.
+	Link      string      // Hyperlink: a url, or a slide, e.g. "#3" or "#next".
+	Paragraph Paragraph   // Paragraph formatting of the line, it is used from the first element.
 }
 
+// Underline styles of a LineElement.
+const (
+	UnderlineSingle = "sng"
+	UnderlineDouble = "dbl"
+	UnderlineHeavy  = "heavy"
+	UnderlineDotted = "dotted"
+	UnderlineDash   = "dash"
+	UnderlineWavy   = "wavy"
+)
+
+// Baseline offsets of a LineElement.
+const (
+	Superscript = 30
+	Subscript   = -25
+)
+
 // Font specifies the font used in the text box.
 type Font struct {
 	Name string  // E.g. "Courier New"
@@ -54,8 +103,8 @@ func (l LineElement) color() string {
 // addTextBox adds a textbox the the slide's xml tree.
 // The textbox is appended to the slide at the path:
 // <p:sld...><p:cSld><p:spTree>
-func (s *Slide) addTextBox(tb TextBox, tbNum int) error {
-	if err := tb.build(tbNum); err != nil {
+func (s *Slide) addTextBox(tb TextBox) error {
+	if err := tb.build(s.ids.new("TextBox", "")); err != nil {
 		return err
 	}
 	root := s.xml.Root()
@@ -75,24 +124,26 @@ func (s *Slide) addTextBox(tb TextBox, tbNum int) error {
 }
 
 // build creates the xml tree of a text box.
-func (tb *TextBox) build(tbNum int) error {
-	numStr := strconv.Itoa(tbNum + 1)
+func (tb *TextBox) build(id int, name string) error {
 	x := strconv.FormatUint(uint64(tb.X), 10)
 	y := strconv.FormatUint(uint64(tb.Y), 10)
+	w, h := tb.size()
+	cx := strconv.FormatUint(uint64(w), 10)
+	cy := strconv.FormatUint(uint64(h), 10)
 	template := `<p:sp>
 <p:nvSpPr>
-<p:cNvPr id="` + strconv.Itoa(tbNum+2) + `" name="TextBox ` + numStr + `"/>
+<p:cNvPr id="` + strconv.Itoa(id) + `" name="` + escape(name) + `"/>
 <p:cNvSpPr txBox="1"/>
 <p:nvPr/>
 </p:nvSpPr>
 <p:spPr>
 <a:xfrm>
 <a:off x="` + x + `" y="` + y + `"/>
-<a:ext cx="360000" cy="360000"/>
+<a:ext cx="` + cx + `" cy="` + cy + `"/>
 </a:xfrm>
 </p:spPr>
 <p:txBody>
-<a:bodyPr wrap="none" rtlCol="0">
+<a:bodyPr rtlCol="0">
 </a:bodyPr>
 </p:txBody>
 </p:sp>`
@@ -104,6 +155,7 @@ func (tb *TextBox) build(tbNum int) error {
 	if txBody == nil {
 		return fmt.Errorf("cannot find txBody")
 	}
+	tb.bodyPr(txBody.SelectElement("a:bodyPr"))
 	if tb.Title {
 		nvPr := tb.xml.FindElement("p:sp/p:nvSpPr/p:nvPr")
 		if nvPr == nil {
@@ -118,38 +170,103 @@ func (tb *TextBox) build(tbNum int) error {
 	return nil
 }
 
+// bodyPr sets the text body properties: wrapping, insets, anchor, columns and autofit.
+func (tb TextBox) bodyPr(bodyPr *etree.Element) {
+	if tb.Wrap {
+		bodyPr.CreateAttr("wrap", "square")
+	} else {
+		bodyPr.CreateAttr("wrap", "none")
+	}
+	if in := tb.Insets; in != nil {
+		bodyPr.CreateAttr("lIns", strconv.FormatUint(uint64(in.Left), 10))
+		bodyPr.CreateAttr("tIns", strconv.FormatUint(uint64(in.Top), 10))
+		bodyPr.CreateAttr("rIns", strconv.FormatUint(uint64(in.Right), 10))
+		bodyPr.CreateAttr("bIns", strconv.FormatUint(uint64(in.Bottom), 10))
+	}
+	if tb.Columns > 1 {
+		bodyPr.CreateAttr("numCol", strconv.Itoa(tb.Columns))
+		if tb.ColumnSpacing > 0 {
+			bodyPr.CreateAttr("spcCol", strconv.FormatUint(uint64(tb.ColumnSpacing), 10))
+		}
+	}
+	if tb.Anchor != "" {
+		bodyPr.CreateAttr("anchor", tb.Anchor)
+	}
+	if tb.Autofit != AutofitNone {
+		bodyPr.CreateElement("a:" + tb.Autofit)
+	} else if tb.Height > 0 {
+		bodyPr.CreateElement("a:noAutofit")
+	}
+}
+
+// size returns the size of the text box.
+// Missing values are estimated from the number of characters and the font size,
+// assuming an average character width of 0.55 and a line height of 1.2 times the font size.
+func (tb TextBox) size() (Dimension, Dimension) {
+	if tb.Width > 0 && tb.Height > 0 {
+		return tb.Width, tb.Height
+	}
+	in := defaultInsets
+	if tb.Insets != nil {
+		in = *tb.Insets
+	}
+	const pt = 12700
+	avail := 0.0
+	if tb.Wrap && tb.Width > in.Left+in.Right {
+		avail = float64(tb.Width - in.Left - in.Right)
+	}
+	var w, h float64
+	for _, line := range tb.Lines {
+		size := tb.Font.Size
+		if size == 0 {
+			size = 18
+		}
+		lw := 0.0
+		for _, e := range line {
+			s := size
+			if e.Font.Size > 0 {
+				s = e.Font.Size
+			}
+			if s > size {
+				size = s
+			}
+			lw += 0.55 * s * pt * float64(len([]rune(e.Text)))
+		}
+		n := 1.0
+		if avail > 0 && lw > avail {
+			n = math.Ceil(lw / avail)
+			lw = avail
+		}
+		if lw > w {
+			w = lw
+		}
+		h += n * 1.2 * size * pt
+	}
+	if columns := float64(tb.Columns); columns > 1 {
+		h = math.Ceil(h / columns)
+		w = w*columns + float64(tb.ColumnSpacing)*(columns-1)
+	}
+	width, height := tb.Width, tb.Height
+	if width == 0 {
+		width = Dimension(w) + in.Left + in.Right
+	}
+	if height == 0 {
+		height = Dimension(h) + in.Top + in.Bottom
+	}
+	return width, height
+}
+
 // buildLine returns the xml tree for a text box line.
 func (tb TextBox) buildLine(line Line) *etree.Document {
 	doc := etree.NewDocument()
 	ap := doc.CreateElement("a:p")
+	if len(line) > 0 {
+		line[0].Paragraph.apply(ap)
+	}
 	for _, word := range line {
 		ar := ap.CreateElement("a:r")
-		if tb.Font.Size > 0 || tb.Font.Name != "" {
-			arPr := ar.CreateElement("a:rPr")
-			if s := int(tb.Font.Size * 100); s > 0 {
-				arPr.Attr = []etree.Attr{
-					etree.Attr{Key: "sz", Value: strconv.Itoa(s)},
-				}
-			}
-
-			if word.Color != nil {
-				asolidFill := arPr.CreateElement("a:solidFill")
-				asrgbClr := asolidFill.CreateElement("a:srgbClr")
-				asrgbClr.Attr = []etree.Attr{
-					etree.Attr{Key: "val", Value: word.color()},
-				}
-			}
-
-			if tb.Font.Name != "" {
-				alatin := arPr.CreateElement("a:latin")
-				alatin.Attr = []etree.Attr{
-					etree.Attr{Key: "typeface", Value: tb.Font.Name},
-				}
-				acs := arPr.CreateElement("a:cs")
-				acs.Attr = []etree.Attr{
-					etree.Attr{Key: "typeface", Value: tb.Font.Name},
-				}
-			}
+		if rPr := word.rPr(tb.Font); rPr != nil {
+			ar.AddChild(rPr)
 		}
 		at := ar.CreateElement("a:t")
 		at.CreateCharData(word.Text)
@@ -157,6 +274,56 @@ func (tb TextBox) buildLine(line Line) *etree.Document {
 	return doc
 }
 
+// rPr returns the run properties of a line element, or nil if there are none.
+// The font of the element overrides the default font of the text box.
+func (l LineElement) rPr(font Font) *etree.Element {
+	if l.Font.Name != "" {
+		font.Name = l.Font.Name
+	}
+	if l.Font.Size > 0 {
+		font.Size = l.Font.Size
+	}
+	rPr := etree.NewElement("a:rPr")
+	if s := int(font.Size * 100); s > 0 {
+		rPr.CreateAttr("sz", strconv.Itoa(s))
+	}
+	if l.Bold {
+		rPr.CreateAttr("b", "1")
+	}
+	if l.Italic {
+		rPr.CreateAttr("i", "1")
+	}
+	if l.Underline != "" {
+		rPr.CreateAttr("u", l.Underline)
+	}
+	if l.Strike {
+		rPr.CreateAttr("strike", "sngStrike")
+	}
+	if l.Spacing != 0 {
+		rPr.CreateAttr("spc", strconv.Itoa(int(l.Spacing*100)))
+	}
+	if l.Baseline != 0 {
+		rPr.CreateAttr("baseline", strconv.Itoa(l.Baseline*1000))
+	}
+	if l.Color != nil {
+		rPr.CreateElement("a:solidFill").CreateElement("a:srgbClr").CreateAttr("val", l.color())
+	}
+	if l.Highlight != nil {
+		rPr.CreateElement("a:highlight").CreateElement("a:srgbClr").CreateAttr("val", rgb(l.Highlight))
+	}
+	if font.Name != "" {
+		rPr.CreateElement("a:latin").CreateAttr("typeface", font.Name)
+		rPr.CreateElement("a:cs").CreateAttr("typeface", font.Name)
+	}
+	if l.Link != "" {
+		hlinkClick(rPr, l.Link)
+	}
+	if len(rPr.Attr) == 0 && len(rPr.Child) == 0 {
+		return nil
+	}
+	return rPr
+}
+
 // A textbox only needs an addition to ppt/slides/slideN.xml
 // The node <p:sp> should be inserted to the path:
 // <p:sld...><p:cSld><p:spTree> after <p:grpSpPr>